@@ -0,0 +1,608 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: api/chat/v1/chat.proto
+
+package chatv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// MessageType is a type of message, numbers are the same as numeric types of chat.v1.json WebSocket protocol.
+type MessageType int32
+
+const (
+	MessageType_MESSAGE_TYPE_SETTINGS    MessageType = 0
+	MessageType_MESSAGE_TYPE_TEXT        MessageType = 1
+	MessageType_MESSAGE_TYPE_ROOM_JOIN   MessageType = 2
+	MessageType_MESSAGE_TYPE_ROOM_LEAVE  MessageType = 3
+	MessageType_MESSAGE_TYPE_ROOM_TEXT   MessageType = 4
+	MessageType_MESSAGE_TYPE_ERROR       MessageType = 5
+	MessageType_MESSAGE_TYPE_HISTORY     MessageType = 6
+	MessageType_MESSAGE_TYPE_ONLINE      MessageType = 7
+	MessageType_MESSAGE_TYPE_PRESENCE    MessageType = 8
+	MessageType_MESSAGE_TYPE_TYPING      MessageType = 9
+	MessageType_MESSAGE_TYPE_FILE_OFFER  MessageType = 10
+	MessageType_MESSAGE_TYPE_FILE_ANSWER MessageType = 11
+	MessageType_MESSAGE_TYPE_FILE_CHUNK  MessageType = 12
+	MessageType_MESSAGE_TYPE_EDIT        MessageType = 13
+	MessageType_MESSAGE_TYPE_DELETE      MessageType = 14
+	MessageType_MESSAGE_TYPE_REACT       MessageType = 15
+)
+
+// Enum value maps for MessageType.
+var (
+	MessageType_name = map[int32]string{
+		0:  "MESSAGE_TYPE_SETTINGS",
+		1:  "MESSAGE_TYPE_TEXT",
+		2:  "MESSAGE_TYPE_ROOM_JOIN",
+		3:  "MESSAGE_TYPE_ROOM_LEAVE",
+		4:  "MESSAGE_TYPE_ROOM_TEXT",
+		5:  "MESSAGE_TYPE_ERROR",
+		6:  "MESSAGE_TYPE_HISTORY",
+		7:  "MESSAGE_TYPE_ONLINE",
+		8:  "MESSAGE_TYPE_PRESENCE",
+		9:  "MESSAGE_TYPE_TYPING",
+		10: "MESSAGE_TYPE_FILE_OFFER",
+		11: "MESSAGE_TYPE_FILE_ANSWER",
+		12: "MESSAGE_TYPE_FILE_CHUNK",
+		13: "MESSAGE_TYPE_EDIT",
+		14: "MESSAGE_TYPE_DELETE",
+		15: "MESSAGE_TYPE_REACT",
+	}
+	MessageType_value = map[string]int32{
+		"MESSAGE_TYPE_SETTINGS":    0,
+		"MESSAGE_TYPE_TEXT":        1,
+		"MESSAGE_TYPE_ROOM_JOIN":   2,
+		"MESSAGE_TYPE_ROOM_LEAVE":  3,
+		"MESSAGE_TYPE_ROOM_TEXT":   4,
+		"MESSAGE_TYPE_ERROR":       5,
+		"MESSAGE_TYPE_HISTORY":     6,
+		"MESSAGE_TYPE_ONLINE":      7,
+		"MESSAGE_TYPE_PRESENCE":    8,
+		"MESSAGE_TYPE_TYPING":      9,
+		"MESSAGE_TYPE_FILE_OFFER":  10,
+		"MESSAGE_TYPE_FILE_ANSWER": 11,
+		"MESSAGE_TYPE_FILE_CHUNK":  12,
+		"MESSAGE_TYPE_EDIT":        13,
+		"MESSAGE_TYPE_DELETE":      14,
+		"MESSAGE_TYPE_REACT":       15,
+	}
+)
+
+func (x MessageType) Enum() *MessageType {
+	p := new(MessageType)
+	*p = x
+	return p
+}
+
+func (x MessageType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MessageType) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_chat_v1_chat_proto_enumTypes[0].Descriptor()
+}
+
+func (MessageType) Type() protoreflect.EnumType {
+	return &file_api_chat_v1_chat_proto_enumTypes[0]
+}
+
+func (x MessageType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MessageType.Descriptor instead.
+func (MessageType) EnumDescriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{0}
+}
+
+// Envelope is any message from client or to client, fields are fields of WebSocket message with the same name, message
+// of type has only fields WebSocket message of this type has. File chunk is a message with data, instead of binary
+// frame.
+type Envelope struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  MessageType            `protobuf:"varint,1,opt,name=type,proto3,enum=chat.v1.MessageType" json:"type,omitempty"`
+	Id    string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Ref   string                 `protobuf:"bytes,3,opt,name=ref,proto3" json:"ref,omitempty"`
+	From  string                 `protobuf:"bytes,4,opt,name=from,proto3" json:"from,omitempty"`
+	To    string                 `protobuf:"bytes,5,opt,name=to,proto3" json:"to,omitempty"`
+	Room  string                 `protobuf:"bytes,6,opt,name=room,proto3" json:"room,omitempty"`
+	Text  string                 `protobuf:"bytes,7,opt,name=text,proto3" json:"text,omitempty"`
+	Time  *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=time,proto3" json:"time,omitempty"`
+	// code is error code, e.g. "unknown_recipient"
+	Code string `protobuf:"bytes,9,opt,name=code,proto3" json:"code,omitempty"`
+	// settings and presence
+	ClientId       string `protobuf:"bytes,10,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	ResumeToken    string `protobuf:"bytes,11,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	MaxMessageSize int32  `protobuf:"varint,12,opt,name=max_message_size,json=maxMessageSize,proto3" json:"max_message_size,omitempty"`
+	MaxTextLength  int32  `protobuf:"varint,13,opt,name=max_text_length,json=maxTextLength,proto3" json:"max_text_length,omitempty"`
+	Protocol       string `protobuf:"bytes,14,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Online         bool   `protobuf:"varint,15,opt,name=online,proto3" json:"online,omitempty"`
+	// history, online and typing
+	With     string      `protobuf:"bytes,16,opt,name=with,proto3" json:"with,omitempty"`
+	Before   string      `protobuf:"bytes,17,opt,name=before,proto3" json:"before,omitempty"`
+	Limit    int32       `protobuf:"varint,18,opt,name=limit,proto3" json:"limit,omitempty"`
+	Messages []*Envelope `protobuf:"bytes,19,rep,name=messages,proto3" json:"messages,omitempty"`
+	Clients  []string    `protobuf:"bytes,20,rep,name=clients,proto3" json:"clients,omitempty"`
+	Typing   bool        `protobuf:"varint,21,opt,name=typing,proto3" json:"typing,omitempty"`
+	// file transfer
+	FileId string `protobuf:"bytes,22,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Name   string `protobuf:"bytes,23,opt,name=name,proto3" json:"name,omitempty"`
+	Size   int64  `protobuf:"varint,24,opt,name=size,proto3" json:"size,omitempty"`
+	Mime   string `protobuf:"bytes,25,opt,name=mime,proto3" json:"mime,omitempty"`
+	Accept bool   `protobuf:"varint,26,opt,name=accept,proto3" json:"accept,omitempty"`
+	Seq    int32  `protobuf:"varint,27,opt,name=seq,proto3" json:"seq,omitempty"`
+	Sha256 string `protobuf:"bytes,28,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	Data   []byte `protobuf:"bytes,29,opt,name=data,proto3" json:"data,omitempty"`
+	// edit, delete and react
+	MessageId     string      `protobuf:"bytes,30,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Reaction      string      `protobuf:"bytes,31,opt,name=reaction,proto3" json:"reaction,omitempty"`
+	Edited        bool        `protobuf:"varint,32,opt,name=edited,proto3" json:"edited,omitempty"`
+	Deleted       bool        `protobuf:"varint,33,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	Reactions     []*Reaction `protobuf:"bytes,34,rep,name=reactions,proto3" json:"reactions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Envelope) Reset() {
+	*x = Envelope{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Envelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Envelope) ProtoMessage() {}
+
+func (x *Envelope) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Envelope.ProtoReflect.Descriptor instead.
+func (*Envelope) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Envelope) GetType() MessageType {
+	if x != nil {
+		return x.Type
+	}
+	return MessageType_MESSAGE_TYPE_SETTINGS
+}
+
+func (x *Envelope) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Envelope) GetRef() string {
+	if x != nil {
+		return x.Ref
+	}
+	return ""
+}
+
+func (x *Envelope) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *Envelope) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *Envelope) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *Envelope) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Envelope) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Envelope) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Envelope) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *Envelope) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+func (x *Envelope) GetMaxMessageSize() int32 {
+	if x != nil {
+		return x.MaxMessageSize
+	}
+	return 0
+}
+
+func (x *Envelope) GetMaxTextLength() int32 {
+	if x != nil {
+		return x.MaxTextLength
+	}
+	return 0
+}
+
+func (x *Envelope) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *Envelope) GetOnline() bool {
+	if x != nil {
+		return x.Online
+	}
+	return false
+}
+
+func (x *Envelope) GetWith() string {
+	if x != nil {
+		return x.With
+	}
+	return ""
+}
+
+func (x *Envelope) GetBefore() string {
+	if x != nil {
+		return x.Before
+	}
+	return ""
+}
+
+func (x *Envelope) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *Envelope) GetMessages() []*Envelope {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *Envelope) GetClients() []string {
+	if x != nil {
+		return x.Clients
+	}
+	return nil
+}
+
+func (x *Envelope) GetTyping() bool {
+	if x != nil {
+		return x.Typing
+	}
+	return false
+}
+
+func (x *Envelope) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *Envelope) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Envelope) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Envelope) GetMime() string {
+	if x != nil {
+		return x.Mime
+	}
+	return ""
+}
+
+func (x *Envelope) GetAccept() bool {
+	if x != nil {
+		return x.Accept
+	}
+	return false
+}
+
+func (x *Envelope) GetSeq() int32 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Envelope) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *Envelope) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Envelope) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *Envelope) GetReaction() string {
+	if x != nil {
+		return x.Reaction
+	}
+	return ""
+}
+
+func (x *Envelope) GetEdited() bool {
+	if x != nil {
+		return x.Edited
+	}
+	return false
+}
+
+func (x *Envelope) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+func (x *Envelope) GetReactions() []*Reaction {
+	if x != nil {
+		return x.Reactions
+	}
+	return nil
+}
+
+// Reaction is a reaction to message and IDs of clients, who added it.
+type Reaction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reaction      string                 `protobuf:"bytes,1,opt,name=reaction,proto3" json:"reaction,omitempty"`
+	ClientIds     []string               `protobuf:"bytes,2,rep,name=client_ids,json=clientIds,proto3" json:"client_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reaction) Reset() {
+	*x = Reaction{}
+	mi := &file_api_chat_v1_chat_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reaction) ProtoMessage() {}
+
+func (x *Reaction) ProtoReflect() protoreflect.Message {
+	mi := &file_api_chat_v1_chat_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reaction.ProtoReflect.Descriptor instead.
+func (*Reaction) Descriptor() ([]byte, []int) {
+	return file_api_chat_v1_chat_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Reaction) GetReaction() string {
+	if x != nil {
+		return x.Reaction
+	}
+	return ""
+}
+
+func (x *Reaction) GetClientIds() []string {
+	if x != nil {
+		return x.ClientIds
+	}
+	return nil
+}
+
+var File_api_chat_v1_chat_proto protoreflect.FileDescriptor
+
+const file_api_chat_v1_chat_proto_rawDesc = "" +
+	"\n" +
+	"\x16api/chat/v1/chat.proto\x12\achat.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x98\a\n" +
+	"\bEnvelope\x12(\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x14.chat.v1.MessageTypeR\x04type\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x10\n" +
+	"\x03ref\x18\x03 \x01(\tR\x03ref\x12\x12\n" +
+	"\x04from\x18\x04 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x05 \x01(\tR\x02to\x12\x12\n" +
+	"\x04room\x18\x06 \x01(\tR\x04room\x12\x12\n" +
+	"\x04text\x18\a \x01(\tR\x04text\x12.\n" +
+	"\x04time\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x12\n" +
+	"\x04code\x18\t \x01(\tR\x04code\x12\x1b\n" +
+	"\tclient_id\x18\n" +
+	" \x01(\tR\bclientId\x12!\n" +
+	"\fresume_token\x18\v \x01(\tR\vresumeToken\x12(\n" +
+	"\x10max_message_size\x18\f \x01(\x05R\x0emaxMessageSize\x12&\n" +
+	"\x0fmax_text_length\x18\r \x01(\x05R\rmaxTextLength\x12\x1a\n" +
+	"\bprotocol\x18\x0e \x01(\tR\bprotocol\x12\x16\n" +
+	"\x06online\x18\x0f \x01(\bR\x06online\x12\x12\n" +
+	"\x04with\x18\x10 \x01(\tR\x04with\x12\x16\n" +
+	"\x06before\x18\x11 \x01(\tR\x06before\x12\x14\n" +
+	"\x05limit\x18\x12 \x01(\x05R\x05limit\x12-\n" +
+	"\bmessages\x18\x13 \x03(\v2\x11.chat.v1.EnvelopeR\bmessages\x12\x18\n" +
+	"\aclients\x18\x14 \x03(\tR\aclients\x12\x16\n" +
+	"\x06typing\x18\x15 \x01(\bR\x06typing\x12\x17\n" +
+	"\afile_id\x18\x16 \x01(\tR\x06fileId\x12\x12\n" +
+	"\x04name\x18\x17 \x01(\tR\x04name\x12\x12\n" +
+	"\x04size\x18\x18 \x01(\x03R\x04size\x12\x12\n" +
+	"\x04mime\x18\x19 \x01(\tR\x04mime\x12\x16\n" +
+	"\x06accept\x18\x1a \x01(\bR\x06accept\x12\x10\n" +
+	"\x03seq\x18\x1b \x01(\x05R\x03seq\x12\x16\n" +
+	"\x06sha256\x18\x1c \x01(\tR\x06sha256\x12\x12\n" +
+	"\x04data\x18\x1d \x01(\fR\x04data\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x1e \x01(\tR\tmessageId\x12\x1a\n" +
+	"\breaction\x18\x1f \x01(\tR\breaction\x12\x16\n" +
+	"\x06edited\x18  \x01(\bR\x06edited\x12\x18\n" +
+	"\adeleted\x18! \x01(\bR\adeleted\x12/\n" +
+	"\treactions\x18\" \x03(\v2\x11.chat.v1.ReactionR\treactions\"E\n" +
+	"\bReaction\x12\x1a\n" +
+	"\breaction\x18\x01 \x01(\tR\breaction\x12\x1d\n" +
+	"\n" +
+	"client_ids\x18\x02 \x03(\tR\tclientIds*\xb3\x03\n" +
+	"\vMessageType\x12\x19\n" +
+	"\x15MESSAGE_TYPE_SETTINGS\x10\x00\x12\x15\n" +
+	"\x11MESSAGE_TYPE_TEXT\x10\x01\x12\x1a\n" +
+	"\x16MESSAGE_TYPE_ROOM_JOIN\x10\x02\x12\x1b\n" +
+	"\x17MESSAGE_TYPE_ROOM_LEAVE\x10\x03\x12\x1a\n" +
+	"\x16MESSAGE_TYPE_ROOM_TEXT\x10\x04\x12\x16\n" +
+	"\x12MESSAGE_TYPE_ERROR\x10\x05\x12\x18\n" +
+	"\x14MESSAGE_TYPE_HISTORY\x10\x06\x12\x17\n" +
+	"\x13MESSAGE_TYPE_ONLINE\x10\a\x12\x19\n" +
+	"\x15MESSAGE_TYPE_PRESENCE\x10\b\x12\x17\n" +
+	"\x13MESSAGE_TYPE_TYPING\x10\t\x12\x1b\n" +
+	"\x17MESSAGE_TYPE_FILE_OFFER\x10\n" +
+	"\x12\x1c\n" +
+	"\x18MESSAGE_TYPE_FILE_ANSWER\x10\v\x12\x1b\n" +
+	"\x17MESSAGE_TYPE_FILE_CHUNK\x10\f\x12\x15\n" +
+	"\x11MESSAGE_TYPE_EDIT\x10\r\x12\x17\n" +
+	"\x13MESSAGE_TYPE_DELETE\x10\x0e\x12\x16\n" +
+	"\x12MESSAGE_TYPE_REACT\x10\x0f2?\n" +
+	"\vChatService\x120\n" +
+	"\x04Chat\x12\x11.chat.v1.Envelope\x1a\x11.chat.v1.Envelope(\x010\x01BY\n" +
+	"#com.github.dark705.gowschat.chat.v1P\x01Z0github.com/dark705/go-ws-chat/api/chat/v1;chatv1b\x06proto3"
+
+var (
+	file_api_chat_v1_chat_proto_rawDescOnce sync.Once
+	file_api_chat_v1_chat_proto_rawDescData []byte
+)
+
+func file_api_chat_v1_chat_proto_rawDescGZIP() []byte {
+	file_api_chat_v1_chat_proto_rawDescOnce.Do(func() {
+		file_api_chat_v1_chat_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_chat_v1_chat_proto_rawDesc), len(file_api_chat_v1_chat_proto_rawDesc)))
+	})
+	return file_api_chat_v1_chat_proto_rawDescData
+}
+
+var file_api_chat_v1_chat_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_api_chat_v1_chat_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_api_chat_v1_chat_proto_goTypes = []any{
+	(MessageType)(0),              // 0: chat.v1.MessageType
+	(*Envelope)(nil),              // 1: chat.v1.Envelope
+	(*Reaction)(nil),              // 2: chat.v1.Reaction
+	(*timestamppb.Timestamp)(nil), // 3: google.protobuf.Timestamp
+}
+var file_api_chat_v1_chat_proto_depIdxs = []int32{
+	0, // 0: chat.v1.Envelope.type:type_name -> chat.v1.MessageType
+	3, // 1: chat.v1.Envelope.time:type_name -> google.protobuf.Timestamp
+	1, // 2: chat.v1.Envelope.messages:type_name -> chat.v1.Envelope
+	2, // 3: chat.v1.Envelope.reactions:type_name -> chat.v1.Reaction
+	1, // 4: chat.v1.ChatService.Chat:input_type -> chat.v1.Envelope
+	1, // 5: chat.v1.ChatService.Chat:output_type -> chat.v1.Envelope
+	5, // [5:6] is the sub-list for method output_type
+	4, // [4:5] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_api_chat_v1_chat_proto_init() }
+func file_api_chat_v1_chat_proto_init() {
+	if File_api_chat_v1_chat_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_chat_v1_chat_proto_rawDesc), len(file_api_chat_v1_chat_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_chat_v1_chat_proto_goTypes,
+		DependencyIndexes: file_api_chat_v1_chat_proto_depIdxs,
+		EnumInfos:         file_api_chat_v1_chat_proto_enumTypes,
+		MessageInfos:      file_api_chat_v1_chat_proto_msgTypes,
+	}.Build()
+	File_api_chat_v1_chat_proto = out.File
+	file_api_chat_v1_chat_proto_goTypes = nil
+	file_api_chat_v1_chat_proto_depIdxs = nil
+}
@@ -6,13 +6,20 @@ import (
 	"os/signal"
 	"syscall"
 
+	chatv1 "github.com/dark705/go-ws-chat/api/chat/v1"
+	"github.com/dark705/go-ws-chat/internal/auth"
+	"github.com/dark705/go-ws-chat/internal/bot"
 	"github.com/dark705/go-ws-chat/internal/chat"
 	"github.com/dark705/go-ws-chat/internal/config"
+	"github.com/dark705/go-ws-chat/internal/grpcserver"
+	"github.com/dark705/go-ws-chat/internal/history"
 	"github.com/dark705/go-ws-chat/internal/httpserver"
 	"github.com/dark705/go-ws-chat/internal/kuberprobe"
 	"github.com/dark705/go-ws-chat/internal/prometheus"
 	"github.com/dark705/go-ws-chat/internal/pubsub"
 	"github.com/dark705/go-ws-chat/internal/slog"
+	"github.com/dark705/go-ws-chat/internal/tcpserver"
+	"github.com/dark705/go-ws-chat/internal/webhook"
 	"github.com/gorilla/websocket"
 	promhttpmetrics "github.com/slok/go-http-metrics/metrics/prometheus"
 	promhttpmiddleware "github.com/slok/go-http-metrics/middleware"
@@ -25,28 +32,134 @@ func main() {
 	logger := slog.New(slog.Config{Level: envConfig.LogLevel})
 	logger.Infof("app, version: %s", envConfig.Version)
 
-	prometheusServer := prometheus.NewServer(prometheus.Config{HTTPListenPort: envConfig.PrometheusPort}, logger)
+	chatMetrics := prometheus.NewChatMetrics()
+	prometheusServer := prometheus.NewServer(prometheus.Config{HTTPListenPort: envConfig.PrometheusPort}, logger,
+		chatMetrics.Collectors()...)
 	prometheusServer.Run()
 	defer prometheusServer.Stop()
 
 	wsUpgrader := &websocket.Upgrader{
-		ReadBufferSize:  envConfig.WebSocketUpgraderReadBufferSize,
-		WriteBufferSize: envConfig.WebSocketUpgraderWriteBufferSize,
+		ReadBufferSize:    envConfig.WebSocketUpgraderReadBufferSize,
+		WriteBufferSize:   envConfig.WebSocketUpgraderWriteBufferSize,
+		EnableCompression: envConfig.WebSocketUpgraderCompression,
 	}
 	if !envConfig.WebSocketUpgraderCheckOrigin {
 		wsUpgrader.CheckOrigin = func(_ *http.Request) bool { return true }
 	}
 
 	// ps := pubsub.NewRndEcho(logger)
-	pubSubHubInMemory := pubsub.NewInmemory(logger)
+	pubSubHubInMemory := pubsub.NewInmemory(pubsub.Config{
+		OfflineQueueSize:          envConfig.PubSubOfflineQueueSize,
+		OfflineQueueTTLSeconds:    envConfig.PubSubOfflineQueueTTLSeconds,
+		OfflineQueueMaxRecipients: envConfig.PubSubOfflineQueueMaxRecipients,
+		OfflineQueueMaxBytes:      envConfig.PubSubOfflineQueueMaxBytes,
+	}, logger)
+
+	var historyStore chat.HistoryStore
+	switch envConfig.HistoryStore {
+	case config.HistoryStoreBolt:
+		historyStoreBolt := history.NewBolt(logger, envConfig.HistoryBoltPath)
+		defer historyStoreBolt.Close() //nolint:errcheck
+		historyStore = historyStoreBolt
+	default:
+		historyStore = history.NewInmemory(logger, envConfig.HistoryInmemoryMaxPerConversation)
+	}
+
+	var bots []chat.Bot
+	var helpBot bool
+	for _, botName := range envConfig.Bots {
+		switch botName {
+		case config.BotEcho:
+			bots = append(bots, bot.NewEcho())
+		case config.BotTime:
+			bots = append(bots, bot.NewTime())
+		case config.BotHelp:
+			helpBot = true
+		case config.BotNone:
+		default:
+			logger.Fatalf("app, unknown bot in BOTS: %s", botName)
+		}
+	}
+	if helpBot {
+		bots = append(bots, bot.NewHelp(bots...))
+	}
+	botRunner := chat.NewBotRunner(logger, pubSubHubInMemory, historyStore, bots...)
+	botRunner.Run()
+	defer botRunner.Stop()
+
+	if envConfig.AuthMode != config.AuthModeAnonymous && envConfig.AuthSecret == "" {
+		logger.Fatalf("app, AUTH_SECRET is required for AUTH_MODE: %s", envConfig.AuthMode)
+	}
+
+	var authenticator chat.Authenticator
+	switch envConfig.AuthMode {
+	case config.AuthModeHMAC:
+		authenticator = auth.NewHMAC(envConfig.AuthSecret)
+	case config.AuthModeJWT:
+		authenticator = auth.NewJWT(envConfig.AuthSecret)
+	default:
+		authenticator = auth.NewAnonymous()
+	}
+
+	if len(envConfig.WebhookURLs) > 0 && envConfig.WebhookSecret == "" {
+		logger.Fatalf("app, WEBHOOK_SECRET is required for WEBHOOK_URLS")
+	}
+	webhookDispatcher := webhook.NewDispatcher(webhook.Config{
+		URLs:                     envConfig.WebhookURLs,
+		Secret:                   envConfig.WebhookSecret,
+		QueueSize:                envConfig.WebhookQueueSize,
+		MaxRetries:               envConfig.WebhookMaxRetries,
+		RetryBackoffMilliseconds: envConfig.WebhookRetryBackoffMilliseconds,
+		TimeoutMilliseconds:      envConfig.WebhookTimeoutMilliseconds,
+	}, logger)
+	webhookDispatcher.Run()
+	defer webhookDispatcher.Stop()
+
+	switch envConfig.WebSocketHandlerBackpressurePolicy {
+	case chat.BackpressureDisconnect, chat.BackpressureDropNewest, chat.BackpressureDropOldest, chat.BackpressureBlock:
+	default:
+		logger.Fatalf("app, unknown WEB_SOCKET_HANDLER_BACKPRESSURE_POLICY: %s",
+			envConfig.WebSocketHandlerBackpressurePolicy)
+	}
+	switch envConfig.RateLimitAction {
+	case chat.RateLimitActionWarn, chat.RateLimitActionDrop, chat.RateLimitActionDisconnect:
+	default:
+		logger.Fatalf("app, unknown RATE_LIMIT_ACTION: %s", envConfig.RateLimitAction)
+	}
 
-	chatWSHandler := chat.NewWebSocketHandler(logger, wsUpgrader, chat.ClientConfig{
-		WriteTimeoutSeconds: envConfig.WebSocketHandlerWriteTimeoutSeconds,
-		ReadTimeoutSeconds:  envConfig.WebSocketHandlerReadTimeoutSeconds,
-		ReadLimitPerMessage: envConfig.WebSocketHandlerReadLimitPerMessage,
-		PingIntervalSeconds: envConfig.WebSocketHandlerPingIntervalSeconds,
-	}, pubSubHubInMemory)
+	chatClientConfig := chat.ClientConfig{
+		WriteTimeoutSeconds:   envConfig.WebSocketHandlerWriteTimeoutSeconds,
+		ReadTimeoutSeconds:    envConfig.WebSocketHandlerReadTimeoutSeconds,
+		ReadLimitPerMessage:   envConfig.WebSocketHandlerReadLimitPerMessage,
+		PingIntervalSeconds:   envConfig.WebSocketHandlerPingIntervalSeconds,
+		MaxTextLength:         envConfig.WebSocketHandlerMaxTextLength,
+		TCPIdleTimeoutSeconds: envConfig.TCPIdleTimeoutSeconds,
+		CompressionLevel:      envConfig.WebSocketHandlerCompressionLevel,
+		CompressionMinSize:    envConfig.WebSocketHandlerCompressionMinSize,
+		Backpressure: chat.BackpressureConfig{
+			Policy:                   envConfig.WebSocketHandlerBackpressurePolicy,
+			BlockTimeoutMilliseconds: envConfig.WebSocketHandlerBackpressureBlockTimeoutMilliseconds,
+		},
+		RateLimit: chat.RateLimitConfig{
+			Action:                      envConfig.RateLimitAction,
+			ConnectionMessagesPerSecond: envConfig.RateLimitConnectionMessagesPerSecond,
+			ConnectionBytesPerSecond:    envConfig.RateLimitConnectionBytesPerSecond,
+			IPMessagesPerSecond:         envConfig.RateLimitIPMessagesPerSecond,
+			IPBytesPerSecond:            envConfig.RateLimitIPBytesPerSecond,
+		},
+	}
 
+	chatConnector := chat.NewConnector(logger, authenticator, chatClientConfig, pubSubHubInMemory, historyStore,
+		chat.NewRooms(), chat.NewResumeSessions(envConfig.WebSocketHandlerResumeGraceSeconds), chatMetrics,
+		webhookDispatcher)
+
+	chatWSHandler := chat.NewWebSocketHandler(logger, wsUpgrader, chatConnector)
+	chatSSEHandler := chat.NewSSEHandler(logger, chatConnector)
+	chatLongPollHandler := chat.NewLongPollHandler(logger, chat.LongPollConfig{
+		TimeoutSeconds:     envConfig.LongPollTimeoutSeconds,
+		SessionIdleSeconds: envConfig.LongPollSessionIdleSeconds,
+	}, chatConnector)
+	chatHTTPAPIHandler := chat.NewHTTPAPIHandler(logger, chatConnector)
 	chatHTTPIndexHandler := chat.NewHTTPIndexHandler(logger)
 	httpKuberProbeHandler := kuberprobe.NewHTTPHandler(logger,
 		envConfig.KuberProbeStartupSeconds,
@@ -55,6 +168,18 @@ func main() {
 
 	httpHandler := http.NewServeMux()
 	httpHandler.Handle(chat.HTTPWebSocketRoutePattern, chatWSHandler)
+	httpHandler.Handle(chat.HTTPSSERoutePattern, chatSSEHandler)
+	httpHandler.Handle(chat.HTTPSSESendRoutePattern, chatSSEHandler)
+	httpHandler.Handle(chat.HTTPLongPollOpenRoutePattern, chatLongPollHandler)
+	httpHandler.Handle(chat.HTTPLongPollRoutePattern, chatLongPollHandler)
+	httpHandler.Handle(chat.HTTPLongPollSendRoutePattern, chatLongPollHandler)
+	if envConfig.AuthMode != config.AuthModeAnonymous {
+		httpHandler.Handle(chat.HTTPAPIMessagesRoutePattern, chatHTTPAPIHandler)
+	} else {
+		// anonymous authenticator gives each request new random client ID, so API sender could not be identified
+		logger.Infof("app, %s is disabled for AUTH_MODE: %s, sender must be authenticated",
+			chat.HTTPAPIMessagesEndpoint, envConfig.AuthMode)
+	}
 	httpHandler.Handle(chat.HTTPIndexRoutePattern, chatHTTPIndexHandler)
 	httpHandler.Handle(kuberprobe.HTTPRoutePattern, httpKuberProbeHandler)
 
@@ -75,6 +200,35 @@ func main() {
 	httpServer.Run()
 	defer httpServer.Stop()
 
+	if envConfig.GRPCPort != "" {
+		grpcServer := grpcserver.NewServer(grpcserver.Config{
+			Name:           "go-ws-chat",
+			GRPCListenPort: envConfig.GRPCPort,
+		}, logger, &chatv1.ChatService_ServiceDesc, chat.NewGRPCHandler(logger, chatConnector))
+		grpcServer.Run()
+		defer grpcServer.Stop()
+	}
+
+	if envConfig.TCPLinePort != "" {
+		tcpLineServer := tcpserver.NewServer(tcpserver.Config{
+			Name:           "go-ws-chat-line",
+			TCPListenPort:  envConfig.TCPLinePort,
+			MaxConnections: envConfig.TCPMaxConnections,
+		}, logger, chat.NewLineHandler(logger, chatConnector))
+		tcpLineServer.Run()
+		defer tcpLineServer.Stop()
+	}
+
+	if envConfig.IRCPort != "" {
+		ircServer := tcpserver.NewServer(tcpserver.Config{
+			Name:           "go-ws-chat-irc",
+			TCPListenPort:  envConfig.IRCPort,
+			MaxConnections: envConfig.TCPMaxConnections,
+		}, logger, chat.NewIRCHandler(logger, chatConnector))
+		ircServer.Run()
+		defer ircServer.Stop()
+	}
+
 	osSignals := make(chan os.Signal, 1)
 	signal.Notify(osSignals, syscall.SIGINT, syscall.SIGTERM)
 
@@ -1,12 +1,15 @@
 module github.com/dark705/go-ws-chat
 
-go 1.23
+go 1.23.0
 
 require (
 	github.com/caarlos0/env/v11 v11.2.2
 	github.com/prometheus/client_golang v1.19.0
 	github.com/slok/go-http-metrics v0.12.0
-	go.opentelemetry.io/otel/trace v1.32.0
+	go.etcd.io/bbolt v1.4.3
+	go.opentelemetry.io/otel/trace v1.35.0
+	google.golang.org/grpc v1.73.0
+	google.golang.org/protobuf v1.36.6
 )
 
 require (
@@ -16,7 +19,9 @@ require (
 	github.com/prometheus/client_model v0.6.1 // indirect
 	github.com/prometheus/common v0.53.0 // indirect
 	github.com/prometheus/procfs v0.13.0 // indirect
-	go.opentelemetry.io/otel v1.32.0 // indirect
-	golang.org/x/sys v0.19.0 // indirect
-	google.golang.org/protobuf v1.33.0 // indirect
+	go.opentelemetry.io/otel v1.35.0 // indirect
+	golang.org/x/net v0.38.0 // indirect
+	golang.org/x/sys v0.31.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250324211829-b45e905df463 // indirect
 )
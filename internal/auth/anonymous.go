@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+const anonymousIDSizeBytes = 16
+
+type anonymous struct{}
+
+// NewAnonymous accepts everyone, each connection gets new random client ID.
+func NewAnonymous() *anonymous {
+	return &anonymous{}
+}
+
+func (a *anonymous) Authenticate(_ *http.Request) (string, error) {
+	id := make([]byte, anonymousIDSizeBytes)
+	_, _ = rand.Read(id) // crypto/rand.Read never returns an error
+
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testSecret = "secret"
+
+func hmacToken(secret, subject string, expireAt time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(subject)) + "." + strconv.FormatInt(expireAt.Unix(), 10)
+
+	return payload + "." + sign(secret, payload)
+}
+
+func jwtToken(secret, header, claims string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(header)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(claims))
+
+	return payload + "." + sign(secret, payload)
+}
+
+func sign(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func unix(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
+func TestHMACAuthenticate(t *testing.T) {
+	t.Parallel()
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		token   string
+		want    string
+		wantErr error
+	}{
+		{name: "valid", token: hmacToken(testSecret, "alice", future), want: "alice"},
+		{name: "subject with colon", token: hmacToken(testSecret, "org:alice", future), want: "org:alice"},
+		{name: "no token", wantErr: errNoToken},
+		{name: "wrong secret", token: hmacToken("other", "alice", future), wantErr: errInvalidToken},
+		{name: "expired", token: hmacToken(testSecret, "alice", time.Now().Add(-time.Second)), wantErr: errExpiredToken},
+		{name: "empty subject", token: hmacToken(testSecret, "", future), wantErr: errInvalidToken},
+		{name: "wrong parts count", token: "a.b", wantErr: errInvalidToken},
+		{name: "not a number expire", token: "YQ.x." + sign(testSecret, "YQ.x"), wantErr: errInvalidToken},
+		{name: "tampered subject", token: "Ym9i" + hmacToken(testSecret, "alice", future)[8:], wantErr: errInvalidToken},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			subject, err := NewHMAC(testSecret).Authenticate(tokenRequest(test.token))
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, test.wantErr)
+			}
+			if subject != test.want {
+				t.Fatalf("Authenticate() = %q, want %q", subject, test.want)
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticate(t *testing.T) {
+	t.Parallel()
+	header := `{"alg":"HS256","typ":"JWT"}`
+	exp := unix(time.Now().Add(time.Hour))
+
+	tests := []struct {
+		name    string
+		token   string
+		want    string
+		wantErr error
+	}{
+		{name: "valid", token: jwtToken(testSecret, header, `{"sub":"alice","exp":`+exp+`}`), want: "alice"},
+		{
+			name:  "valid nbf",
+			token: jwtToken(testSecret, header, `{"sub":"alice","exp":`+exp+`,"nbf":`+unix(time.Now().Add(-time.Minute))+`}`),
+			want:  "alice",
+		},
+		{
+			name:    "wrong secret",
+			token:   jwtToken("other", header, `{"sub":"alice","exp":`+exp+`}`),
+			wantErr: errInvalidToken,
+		},
+		{
+			name:    "alg none",
+			token:   jwtToken(testSecret, `{"alg":"none"}`, `{"sub":"alice","exp":`+exp+`}`),
+			wantErr: errInvalidToken,
+		},
+		{
+			name:    "alg RS256",
+			token:   jwtToken(testSecret, `{"alg":"RS256"}`, `{"sub":"alice","exp":`+exp+`}`),
+			wantErr: errInvalidToken,
+		},
+		{
+			name:    "expired",
+			token:   jwtToken(testSecret, header, `{"sub":"alice","exp":`+unix(time.Now().Add(-time.Second))+`}`),
+			wantErr: errExpiredToken,
+		},
+		{
+			name:    "not yet valid",
+			token:   jwtToken(testSecret, header, `{"sub":"alice","exp":`+exp+`,"nbf":`+unix(time.Now().Add(time.Hour))+`}`),
+			wantErr: errExpiredToken,
+		},
+		{name: "no exp", token: jwtToken(testSecret, header, `{"sub":"alice"}`), wantErr: errInvalidToken},
+		{name: "no sub", token: jwtToken(testSecret, header, `{"exp":`+exp+`}`), wantErr: errInvalidToken},
+		{name: "wrong parts count", token: "a.b", wantErr: errInvalidToken},
+		{name: "not JSON claims", token: jwtToken(testSecret, header, `alice`), wantErr: errInvalidToken},
+		{name: "no token", wantErr: errNoToken},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			subject, err := NewJWT(testSecret).Authenticate(tokenRequest(test.token))
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, test.wantErr)
+			}
+			if subject != test.want {
+				t.Fatalf("Authenticate() = %q, want %q", subject, test.want)
+			}
+		})
+	}
+}
+
+func TestTokenFromRequest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		request func() *http.Request
+		want    string
+	}{
+		{name: "query", request: func() *http.Request { return httptest.NewRequest(http.MethodGet, "/ws?token=q", nil) }, want: "q"},
+		{
+			name: "bearer header",
+			request: func() *http.Request {
+				request := httptest.NewRequest(http.MethodGet, "/ws", nil)
+				request.Header.Set("Authorization", "Bearer h")
+
+				return request
+			},
+			want: "h",
+		},
+		{
+			name: "cookie",
+			request: func() *http.Request {
+				request := httptest.NewRequest(http.MethodGet, "/ws", nil)
+				request.AddCookie(&http.Cookie{Name: TokenCookieName, Value: "c"})
+
+				return request
+			},
+			want: "c",
+		},
+		{
+			name: "query first",
+			request: func() *http.Request {
+				request := httptest.NewRequest(http.MethodGet, "/ws?token=q", nil)
+				request.Header.Set("Authorization", "Bearer h")
+
+				return request
+			},
+			want: "q",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			token, err := tokenFromRequest(test.request())
+			if err != nil || token != test.want {
+				t.Fatalf("tokenFromRequest() = %q, %v, want %q", token, err, test.want)
+			}
+		})
+	}
+}
+
+func tokenRequest(token string) *http.Request {
+	request := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return request
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const hmacTokenParts = 3
+
+type hmacAuth struct {
+	secret []byte
+}
+
+// NewHMAC verifies tokens in format: base64url(subject).expireUnixSeconds.base64url(HMAC-SHA256(secret, first two
+// parts joined with dot)).
+func NewHMAC(secret string) *hmacAuth { //nolint:revive
+	return &hmacAuth{secret: []byte(secret)}
+}
+
+func (a *hmacAuth) Authenticate(request *http.Request) (string, error) {
+	subject, _, err := a.AuthenticateUntil(request)
+
+	return subject, err
+}
+
+// AuthenticateUntil returns subject and expiry of token.
+func (a *hmacAuth) AuthenticateUntil(request *http.Request) (string, time.Time, error) {
+	token, err := tokenFromRequest(request)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("auth, hmacAuth, AuthenticateUntil, tokenFromRequest: %w", err)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != hmacTokenParts {
+		return "", time.Time{}, fmt.Errorf("auth, hmacAuth, AuthenticateUntil, wrong parts count: %w", errInvalidToken)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("auth, hmacAuth, AuthenticateUntil, decode signature: %w", errInvalidToken)
+	}
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return "", time.Time{}, fmt.Errorf("auth, hmacAuth, AuthenticateUntil, wrong signature: %w", errInvalidToken)
+	}
+
+	expireAtUnix, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("auth, hmacAuth, AuthenticateUntil, parse expire: %w", errInvalidToken)
+	}
+	expireAt := time.Unix(expireAtUnix, 0)
+	if !time.Now().Before(expireAt) {
+		return "", time.Time{}, fmt.Errorf("auth, hmacAuth, AuthenticateUntil: %w", errExpiredToken)
+	}
+
+	subject, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(subject) == 0 {
+		return "", time.Time{}, fmt.Errorf("auth, hmacAuth, AuthenticateUntil, decode subject: %w", errInvalidToken)
+	}
+
+	return string(subject), expireAt, nil
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	jwtParts       = 3
+	jwtAlgorithmHS = "HS256"
+)
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+type jwtClaims struct {
+	Sub string `json:"sub"`
+	Exp int64  `json:"exp"`
+	Nbf int64  `json:"nbf"`
+}
+
+type jwtAuth struct {
+	secret []byte
+}
+
+// NewJWT verifies HS256 signed JWT, subject is taken from "sub" claim, "exp" claim is required.
+func NewJWT(secret string) *jwtAuth { //nolint:revive
+	return &jwtAuth{secret: []byte(secret)}
+}
+
+func (a *jwtAuth) Authenticate(request *http.Request) (string, error) {
+	subject, _, err := a.AuthenticateUntil(request)
+
+	return subject, err
+}
+
+// AuthenticateUntil returns subject and expiry of token, from "exp" claim.
+func (a *jwtAuth) AuthenticateUntil(request *http.Request) (string, time.Time, error) {
+	token, err := tokenFromRequest(request)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("auth, jwtAuth, AuthenticateUntil, tokenFromRequest: %w", err)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != jwtParts {
+		return "", time.Time{}, fmt.Errorf("auth, jwtAuth, AuthenticateUntil, wrong parts count: %w", errInvalidToken)
+	}
+
+	var header jwtHeader
+	err = decodeJWTPart(parts[0], &header)
+	if err != nil || header.Alg != jwtAlgorithmHS {
+		return "", time.Time{}, fmt.Errorf("auth, jwtAuth, AuthenticateUntil, header alg: %s: %w", header.Alg,
+			errInvalidToken)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("auth, jwtAuth, AuthenticateUntil, decode signature: %w", errInvalidToken)
+	}
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return "", time.Time{}, fmt.Errorf("auth, jwtAuth, AuthenticateUntil, wrong signature: %w", errInvalidToken)
+	}
+
+	var claims jwtClaims
+	err = decodeJWTPart(parts[1], &claims)
+	if err != nil || claims.Sub == "" || claims.Exp == 0 {
+		return "", time.Time{}, fmt.Errorf("auth, jwtAuth, AuthenticateUntil, claims: %w", errInvalidToken)
+	}
+	now := time.Now().Unix()
+	if now >= claims.Exp || now < claims.Nbf {
+		return "", time.Time{}, fmt.Errorf("auth, jwtAuth, AuthenticateUntil: %w", errExpiredToken)
+	}
+
+	return claims.Sub, time.Unix(claims.Exp, 0), nil
+}
+
+func decodeJWTPart(part string, value any) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return fmt.Errorf("base64.DecodeString: %w", err)
+	}
+
+	err = json.Unmarshal(data, value)
+	if err != nil {
+		return fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	return nil
+}
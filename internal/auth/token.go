@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/dark705/go-ws-chat/internal/chat"
+)
+
+const (
+	TokenCookieName = "token"
+
+	authorizationHeader = "Authorization"
+	bearerPrefix        = "Bearer "
+)
+
+var (
+	errNoToken      = errors.New("no token")
+	errInvalidToken = errors.New("invalid token")
+	errExpiredToken = errors.New("expired token")
+)
+
+// tokenFromRequest looks for token in query parameter, Authorization Bearer header and cookie, in that order.
+func tokenFromRequest(request *http.Request) (string, error) {
+	if token := request.URL.Query().Get(chat.TokenQueryParam); token != "" {
+		return token, nil
+	}
+
+	if header := request.Header.Get(authorizationHeader); strings.HasPrefix(header, bearerPrefix) {
+		return strings.TrimPrefix(header, bearerPrefix), nil
+	}
+
+	if cookie, err := request.Cookie(TokenCookieName); err == nil && cookie.Value != "" {
+		return cookie.Value, nil
+	}
+
+	return "", errNoToken
+}
@@ -0,0 +1,28 @@
+package bot
+
+import (
+	"context"
+
+	"github.com/dark705/go-ws-chat/internal/chat"
+)
+
+const EchoID = chat.BotIDPrefix + "echo"
+
+type echo struct{}
+
+// NewEcho replies with the same text, useful to check delivery.
+func NewEcho() *echo { //nolint:revive
+	return &echo{}
+}
+
+func (b *echo) ID() string {
+	return EchoID
+}
+
+func (b *echo) Description() string {
+	return "replies with the same text"
+}
+
+func (b *echo) Reply(_ context.Context, _, text string) (string, error) {
+	return text, nil
+}
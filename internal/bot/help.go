@@ -0,0 +1,37 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dark705/go-ws-chat/internal/chat"
+)
+
+const HelpID = chat.BotIDPrefix + "help"
+
+type help struct {
+	bots []chat.Bot
+}
+
+// NewHelp replies with list of bots and their descriptions, including itself.
+func NewHelp(bots ...chat.Bot) *help { //nolint:revive
+	return &help{bots: bots}
+}
+
+func (b *help) ID() string {
+	return HelpID
+}
+
+func (b *help) Description() string {
+	return "replies with this list"
+}
+
+func (b *help) Reply(_ context.Context, _, _ string) (string, error) {
+	var reply strings.Builder
+	reply.WriteString("bots, send any text to:")
+	for _, bot := range append([]chat.Bot{b}, b.bots...) {
+		reply.WriteString("\n" + bot.ID() + " - " + bot.Description())
+	}
+
+	return reply.String(), nil
+}
@@ -0,0 +1,36 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"time"
+	_ "time/tzdata" // runtime image has no time zone database
+
+	"github.com/dark705/go-ws-chat/internal/chat"
+)
+
+const TimeID = chat.BotIDPrefix + "time"
+
+type timeBot struct{}
+
+// NewTime replies with current server time in time zone from text, e.g. Europe/Berlin, or in UTC for any other text.
+func NewTime() *timeBot { //nolint:revive
+	return &timeBot{}
+}
+
+func (b *timeBot) ID() string {
+	return TimeID
+}
+
+func (b *timeBot) Description() string {
+	return "replies with current time, send time zone (e.g. Europe/Berlin) or anything else for UTC"
+}
+
+func (b *timeBot) Reply(_ context.Context, _, text string) (string, error) {
+	location, err := time.LoadLocation(strings.TrimSpace(text))
+	if err != nil || location == time.Local {
+		location = time.UTC
+	}
+
+	return time.Now().In(location).Format(time.RFC1123), nil
+}
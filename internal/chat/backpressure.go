@@ -0,0 +1,112 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// BackpressureDisconnect closes connection of client, which does not read messages fast enough.
+	BackpressureDisconnect = "disconnect"
+	// BackpressureDropNewest skips message, which does not fit into full buffer of client.
+	BackpressureDropNewest = "drop_newest"
+	// BackpressureDropOldest skips the oldest buffered message of client to make room for new one.
+	BackpressureDropOldest = "drop_oldest"
+	// BackpressureBlock waits for room in buffer of client up to timeout, then disconnects it.
+	BackpressureBlock = "block"
+
+	backpressureResultDropped      = "dropped"
+	backpressureResultDisconnected = "disconnected"
+
+	warnDroppedRetryInterval = 100 * time.Millisecond
+)
+
+type BackpressureConfig struct {
+	Policy                   string
+	BlockTimeoutMilliseconds int
+}
+
+// send puts message into buffer of client according to backpressure policy, false means client must be disconnected.
+// Client is warned with messages_dropped error message about dropped messages, once there is room in buffer.
+func (h *oneToOneHandler) send(ctx context.Context, message frame) bool {
+	h.warnDropped(ctx)
+
+	select {
+	case h.writeCh <- message:
+		return true
+	default:
+	}
+
+	switch h.backpressure.Policy {
+	case BackpressureDropNewest:
+		h.drop(ctx)
+
+		return true
+	case BackpressureDropOldest:
+		select {
+		case <-h.writeCh:
+			h.drop(ctx)
+		default:
+		}
+
+		select {
+		case h.writeCh <- message:
+		default:
+			h.drop(ctx)
+		}
+
+		return true
+	case BackpressureBlock:
+		timer := time.NewTimer(time.Duration(h.backpressure.BlockTimeoutMilliseconds) * time.Millisecond)
+		defer timer.Stop()
+
+		select {
+		case h.writeCh <- message:
+			return true
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+
+	h.metrics.IncBackpressure(h.backpressure.Policy, backpressureResultDisconnected)
+	h.logError(ctx, "chat, oneToOneHandler, send", errFailWriteToClientChan)
+
+	return false
+}
+
+func (h *oneToOneHandler) drop(ctx context.Context) {
+	h.dropped++
+	h.metrics.IncBackpressure(h.backpressure.Policy, backpressureResultDropped)
+	h.logDebug(ctx, "chat, oneToOneHandler, drop", fmt.Sprintf("client buffer is full, dropped: %d", h.dropped))
+}
+
+// warnDroppedRetry fires while client is not warned about dropped messages, for the case no more messages come.
+func (h *oneToOneHandler) warnDroppedRetry() <-chan time.Time {
+	if h.dropped == 0 {
+		return nil
+	}
+
+	return time.After(warnDroppedRetryInterval)
+}
+
+func (h *oneToOneHandler) warnDropped(ctx context.Context) {
+	if h.dropped == 0 || len(h.writeCh) == cap(h.writeCh) {
+		return
+	}
+
+	envelope := h.newEnvelope(messageTypeError, h.clientID, "", fmt.Sprintf("%d messages dropped, too slow reading", h.dropped))
+	envelope.Code = errorCodeMessagesDropped
+	message, err := encodeEnvelope(h.codec, envelope)
+	if err != nil {
+		h.logError(ctx, "chat, oneToOneHandler, warnDropped, encodeEnvelope", err)
+
+		return
+	}
+
+	select {
+	case h.writeCh <- message:
+		h.dropped = 0
+	default:
+	}
+}
@@ -0,0 +1,177 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// BotIDPrefix is a prefix of client IDs reserved for bots, clients are not allowed to connect with such ID.
+	BotIDPrefix = "bot-"
+
+	botInboxSize = 64
+)
+
+var (
+	errReservedClientID   = errors.New("client ID is reserved for bots")
+	errBotIDWithoutPrefix = errors.New("bot ID must start with " + BotIDPrefix)
+	errBotInboxFull       = errors.New("bot inbox is full")
+)
+
+// Bot is an in-process participant of chat, it answers text messages sent to its client ID.
+type Bot interface {
+	// ID is a client ID of bot, it must start with BotIDPrefix.
+	ID() string
+	// Description is a short help about bot usage.
+	Description() string
+	// Reply returns answer to text from client, empty answer is not sent.
+	Reply(ctx context.Context, from, text string) (string, error)
+}
+
+// ActiveBot is a Bot, which also sends messages on its own, e.g. reminders and alerts. Run is called once bot is
+// subscribed and must return when ctx is done.
+type ActiveBot interface {
+	Bot
+	Run(ctx context.Context, sender BotSender)
+}
+
+// BotSender sends text message from bot to client.
+type BotSender interface {
+	Send(ctx context.Context, botID, to, text string) error
+}
+
+type botRunner struct {
+	logger    Logger
+	pubSubHub PubSubHub
+	history   HistoryStore
+	bots      []Bot
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// NewBotRunner registers bots in PubSubHub under their client IDs, messages between clients and bots are saved to
+// history like messages between clients.
+func NewBotRunner(logger Logger, pubSubHub PubSubHub, history HistoryStore, bots ...Bot) *botRunner { //nolint:revive
+	for _, bot := range bots {
+		if !isBotID(bot.ID()) {
+			failOnError(fmt.Errorf("%w: %s", errBotIDWithoutPrefix, bot.ID()), "fail register bot")
+		}
+	}
+
+	return &botRunner{
+		logger:    logger,
+		pubSubHub: pubSubHub,
+		history:   history,
+		bots:      bots,
+	}
+}
+
+func (r *botRunner) Run() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	for _, bot := range r.bots {
+		subCh, err := r.pubSubHub.Sub(ctx, bot.ID())
+		failOnError(err, "fail subscribe bot: "+bot.ID())
+		r.logger.InfofContext(ctx, "chat, botRunner, Run, started bot: %s", bot.ID())
+
+		// publisher waits until subscriber takes envelope, so subscription is drained apart from replies, which may
+		// wait for slow clients, and senders to bot are not blocked: envelopes over full inbox are dropped
+		inbox := make(chan Envelope, botInboxSize)
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			defer close(inbox)
+			for envelope := range subCh {
+				select {
+				case inbox <- envelope:
+				default:
+					r.logError(ctx, "chat, botRunner, Run, bot: "+bot.ID(), errBotInboxFull)
+				}
+			}
+		}()
+
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			for envelope := range inbox {
+				r.reply(ctx, bot, envelope)
+			}
+		}()
+
+		if activeBot, ok := bot.(ActiveBot); ok {
+			r.wg.Add(1)
+			go func() {
+				defer r.wg.Done()
+				activeBot.Run(ctx, r)
+			}()
+		}
+	}
+}
+
+func (r *botRunner) Stop() {
+	r.logger.InfofContext(context.Background(), "chat, botRunner, Stop, stop...")
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	r.logger.InfofContext(context.Background(), "chat, botRunner, Stop, success stop")
+}
+
+// Send publishes text message from bot to client and saves it to history of their conversation.
+func (r *botRunner) Send(ctx context.Context, botID, to, text string) error {
+	envelope := Envelope{
+		Typ:  messageTypeText,
+		ID:   newID(),
+		From: botID,
+		To:   to,
+		Text: text,
+		Time: time.Now().UTC(),
+	}
+	err := r.pubSubHub.Pub(ctx, to, envelope)
+	if err != nil {
+		return fmt.Errorf("pubSubHub.Pub: %w", err)
+	}
+
+	err = r.history.Save(ctx, peerConversation(botID, to), envelope)
+	if err != nil {
+		return fmt.Errorf("history.Save: %w", err)
+	}
+
+	return nil
+}
+
+// reply answers text messages from clients, messages of other types and from bots are ignored, the last prevents
+// bots from talking to each other forever.
+func (r *botRunner) reply(ctx context.Context, bot Bot, envelope Envelope) {
+	if envelope.Typ != messageTypeText || isBotID(envelope.From) {
+		return
+	}
+
+	text, err := bot.Reply(ctx, envelope.From, envelope.Text)
+	if err != nil {
+		r.logError(ctx, "chat, botRunner, reply, bot: "+bot.ID()+", Reply", err)
+
+		return
+	}
+	if text == "" {
+		return
+	}
+
+	err = r.Send(ctx, bot.ID(), envelope.From, text)
+	if err != nil {
+		r.logError(ctx, "chat, botRunner, reply, bot: "+bot.ID()+", Send", err)
+	}
+}
+
+func (r *botRunner) logError(ctx context.Context, point string, err error) {
+	r.logger.ErrorfContext(ctx, "%s, error: %s", point, err)
+}
+
+func isBotID(clientID string) bool {
+	return strings.HasPrefix(clientID, BotIDPrefix)
+}
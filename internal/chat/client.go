@@ -4,36 +4,44 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/gorilla/websocket"
 )
 
-var errWrongWSClientMessageType = errors.New("wrong WebSocket client message type")
-
 type ClientConfig struct {
 	WriteTimeoutSeconds int
 	ReadTimeoutSeconds  int
 	ReadLimitPerMessage int
 	PingIntervalSeconds int
+	// MaxTextLength is a limit of text in characters, validated by oneToOneHandler
+	MaxTextLength int
+	// TCPIdleTimeoutSeconds is a time signed in client of TCP line handler may send nothing, 0 - no limit
+	TCPIdleTimeoutSeconds int
+	// CompressionLevel and CompressionMinSize are used when permessage-deflate is negotiated
+	CompressionLevel   int
+	CompressionMinSize int
+	RateLimit          RateLimitConfig
+	Backpressure       BackpressureConfig
 }
 
 type webSocketClient struct {
-	logger   Logger
-	config   ClientConfig
-	clientID string
-	connect  *websocket.Conn
-	readCh   chan []byte
-	writeCh  chan []byte
+	logger     Logger
+	config     ClientConfig
+	clientID   string
+	connect    *websocket.Conn
+	connection *connection
+	bytes      *connectionBytes
+	metrics    Metrics
 }
 
 func (c *webSocketClient) readPump(ctx context.Context) {
 	defer func() {
 		c.connect.Close()
-		close(c.readCh)
-		c.logDebug(ctx, "chat, webSocketClient, readPump", "stopped")
+		c.connection.close()
+		c.logInfo(ctx, "chat, webSocketClient, readPump", "stopped, clientID: "+c.clientID+", "+c.bytes.String())
 	}()
-	c.connect.SetReadLimit(int64(c.config.ReadLimitPerMessage))
 	c.connect.SetReadDeadline( //nolint:errcheck
 		time.Now().Add(time.Duration(c.config.ReadTimeoutSeconds) * time.Second))
 	c.connect.SetPongHandler(func(string) error {
@@ -45,22 +53,65 @@ func (c *webSocketClient) readPump(ctx context.Context) {
 	})
 
 	for {
-		wsMessageType, message, err := c.connect.ReadMessage()
+		wsMessageType, message, err := c.readFrame()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				c.logError(ctx, "chat, webSocketClient, readPump, connect.ReadMessage", err)
+				c.logError(ctx, "chat, webSocketClient, readPump, readFrame", err)
 			}
 
 			break
 		}
-		c.logDebug(ctx, "chat, webSocketClient, readPump", fmt.Sprintf("received: %s, type: %d", message, wsMessageType))
-		if wsMessageType != websocket.TextMessage {
-			c.logError(ctx, "chat, webSocketClient, readPump, connect.ReadMessage", errWrongWSClientMessageType)
+		switch {
+		case message.tooLarge:
+			c.logDebug(ctx, "chat, webSocketClient, readPump", "received too large message, skipped")
+		case message.binary:
+			c.logDebug(ctx, "chat, webSocketClient, readPump", fmt.Sprintf("received binary, size: %d", len(message.data)))
+		default:
+			c.logDebug(ctx, "chat, webSocketClient, readPump", fmt.Sprintf("received: %s, type: %d", message.data, wsMessageType))
+		}
 
+		_, err = c.connection.read(ctx, message)
+		if errors.Is(err, errRateLimitExceeded) {
+			c.closePolicyViolation(ctx, err.Error())
+		}
+		if err != nil {
 			break
 		}
+	}
+}
+
+// readFrame reads next message, a message over ReadLimitPerMessage is discarded and marked as tooLarge.
+// Errors are returned unwrapped, for websocket.IsUnexpectedCloseError.
+func (c *webSocketClient) readFrame() (int, frame, error) {
+	wsMessageType, reader, err := c.connect.NextReader()
+	if err != nil {
+		return wsMessageType, frame{}, err //nolint:wrapcheck
+	}
+
+	message, err := io.ReadAll(io.LimitReader(reader, int64(c.config.ReadLimitPerMessage)+1))
+	if err != nil {
+		return wsMessageType, frame{}, err //nolint:wrapcheck
+	}
+	c.addPayloadBytes(directionIn, len(message))
+	if len(message) <= c.config.ReadLimitPerMessage {
+		return wsMessageType, frame{data: message, binary: wsMessageType == websocket.BinaryMessage}, nil
+	}
+
+	_, err = io.Copy(io.Discard, reader)
+	if err != nil {
+		return wsMessageType, frame{}, err //nolint:wrapcheck
+	}
+
+	return wsMessageType, frame{tooLarge: true}, nil
+}
 
-		c.readCh <- message
+// closePolicyViolation sends close frame with 1008 code, connection is closed by readPump after.
+func (c *webSocketClient) closePolicyViolation(ctx context.Context, reason string) {
+	err := c.connect.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason),
+		time.Now().Add(time.Duration(c.config.WriteTimeoutSeconds)*time.Second))
+	if err != nil {
+		c.logError(ctx, "chat, webSocketClient, closePolicyViolation, connect.WriteControl", err)
 	}
 }
 
@@ -74,7 +125,7 @@ func (c *webSocketClient) writePump(ctx context.Context) {
 
 	for {
 		select {
-		case message, ok := <-c.writeCh:
+		case message, ok := <-c.connection.writeCh:
 			c.connect.SetWriteDeadline(time.Now().Add(time.Duration(c.config.WriteTimeoutSeconds) * time.Second)) //nolint:errcheck
 			if !ok {
 				c.connect.WriteMessage(websocket.CloseMessage, []byte{}) //nolint:errcheck
@@ -82,13 +133,27 @@ func (c *webSocketClient) writePump(ctx context.Context) {
 				return
 			}
 
-			err := c.connect.WriteMessage(websocket.TextMessage, message)
+			c.connect.EnableWriteCompression(len(message.data) >= c.config.CompressionMinSize)
+			c.addPayloadBytes(directionOut, len(message.data))
+			if message.binary {
+				err := c.connect.WriteMessage(websocket.BinaryMessage, message.data)
+				if err != nil {
+					c.logError(ctx, "chat, webSocketClient, writePump, connect.WriteMessage Binary", err)
+
+					return
+				}
+				c.logDebug(ctx, "chat, webSocketClient, writePump", fmt.Sprintf("sent binary, size: %d", len(message.data)))
+
+				continue
+			}
+
+			err := c.connect.WriteMessage(websocket.TextMessage, message.data)
 			if err != nil {
 				c.logError(ctx, "chat, webSocketClient, writePump, connect.WriteMessage Text", err)
 
 				return
 			}
-			c.logDebug(ctx, "chat, webSocketClient, writePump", fmt.Sprintf("sent: %s", message))
+			c.logDebug(ctx, "chat, webSocketClient, writePump", fmt.Sprintf("sent: %s", message.data))
 
 		case <-ticker.C:
 			c.connect.SetWriteDeadline(time.Now().Add(time.Duration(c.config.WriteTimeoutSeconds) * time.Second)) //nolint:errcheck
@@ -102,10 +167,23 @@ func (c *webSocketClient) writePump(ctx context.Context) {
 	}
 }
 
+func (c *webSocketClient) addPayloadBytes(direction string, bytes int) {
+	if direction == directionIn {
+		c.bytes.payloadIn.Add(int64(bytes))
+	} else {
+		c.bytes.payloadOut.Add(int64(bytes))
+	}
+	c.metrics.AddWebSocketPayloadBytes(direction, bytes)
+}
+
 func (c *webSocketClient) logError(ctx context.Context, point string, err error) {
 	c.logger.ErrorfContext(ctx, "%s, error: %s", point, err)
 }
 
+func (c *webSocketClient) logInfo(ctx context.Context, point, msg string) {
+	c.logger.InfofContext(ctx, "%s, msg: %s", point, msg)
+}
+
 func (c *webSocketClient) logDebug(ctx context.Context, point, msg string) {
 	c.logger.DebugfContext(ctx, "%s, msg: %s", point, msg)
 }
@@ -0,0 +1,66 @@
+package chat
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+)
+
+const (
+	directionIn  = "in"
+	directionOut = "out"
+)
+
+// connectionBytes counts bytes of single connection: wire is as is on network, payload is uncompressed messages.
+type connectionBytes struct {
+	wireIn     atomic.Int64
+	wireOut    atomic.Int64
+	payloadIn  atomic.Int64
+	payloadOut atomic.Int64
+}
+
+func (b *connectionBytes) String() string {
+	return fmt.Sprintf("wire in: %d, wire out: %d, payload in: %d, payload out: %d",
+		b.wireIn.Load(), b.wireOut.Load(), b.payloadIn.Load(), b.payloadOut.Load())
+}
+
+// countingConn counts bytes read from and written to network connection.
+type countingConn struct {
+	net.Conn
+	bytes   *connectionBytes
+	metrics Metrics
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.bytes.wireIn.Add(int64(n))
+	c.metrics.AddWebSocketWireBytes(directionIn, n)
+
+	return n, err //nolint:wrapcheck
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.bytes.wireOut.Add(int64(n))
+	c.metrics.AddWebSocketWireBytes(directionOut, n)
+
+	return n, err //nolint:wrapcheck
+}
+
+// countingResponseWriter wraps hijacked connection into countingConn, for websocket.Upgrader.
+type countingResponseWriter struct {
+	http.ResponseWriter
+	bytes   *connectionBytes
+	metrics Metrics
+}
+
+func (w *countingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, readWriter, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err != nil {
+		return nil, nil, fmt.Errorf("chat, countingResponseWriter, Hijack: %w", err)
+	}
+
+	return &countingConn{Conn: conn, bytes: w.bytes, metrics: w.metrics}, readWriter, nil
+}
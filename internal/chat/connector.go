@@ -0,0 +1,269 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// TokenQueryParam is a query parameter Authenticator implementations expect token in.
+	TokenQueryParam = "token"
+	// ResumeQueryParam is a query parameter with resume token from SettingsMessage of previous connection.
+	ResumeQueryParam = "resume"
+
+	writeChanelBufferSizeBytes = 256
+	replyChanelBufferSize      = 16
+)
+
+var (
+	errRateLimitExceeded = errors.New("rate limit exceeded")
+	errConnectionClosed  = errors.New("connection closed")
+)
+
+// Authenticator verifies request credentials and returns client ID.
+type Authenticator interface {
+	Authenticate(request *http.Request) (string, error)
+}
+
+// ExpiringAuthenticator is Authenticator of credentials with expiry, client can not resume connection after it.
+type ExpiringAuthenticator interface {
+	AuthenticateUntil(request *http.Request) (string, time.Time, error)
+}
+
+// identity is client identified by connector.
+type identity struct {
+	clientID           string
+	credentialExpireAt time.Time // zero if credential does not expire
+}
+
+// connector connects clients of all transports to chat: identifies them, serves their connections with
+// oneToOneHandler and rate limits messages from them. Transports differ only in how they carry messages, so clients
+// of WebSocket, SSE, long polling, TCP, IRC and gRPC chat with each other.
+type connector struct {
+	logger         Logger
+	authenticator  Authenticator
+	config         ClientConfig
+	pubSubHub      PubSubHub
+	history        HistoryStore
+	rooms          *rooms
+	resumeSessions *resumeSessions
+	metrics        Metrics
+	notifier       Notifier
+	ipRateLimits   *ipRateLimits
+}
+
+// connection is a connection of client of any transport. Transport passes messages from client to read and delivers
+// messages to client from writeCh, which is closed when oneToOneHandler of connection stops.
+type connection struct {
+	connector *connector
+	clientID  string
+	// ctx is done when oneToOneHandler of connection stops
+	ctx     context.Context //nolint:containedctx
+	writeCh chan frame
+	limiter *rateLimiter
+
+	mu     sync.Mutex
+	readCh chan frame
+	closed bool
+}
+
+func NewConnector(logger Logger,
+	authenticator Authenticator,
+	config ClientConfig,
+	pubSubHub PubSubHub,
+	history HistoryStore,
+	rooms *rooms,
+	resumeSessions *resumeSessions,
+	metrics Metrics,
+	notifier Notifier) *connector { //nolint:revive
+	return &connector{
+		logger:         logger,
+		authenticator:  authenticator,
+		config:         config,
+		pubSubHub:      pubSubHub,
+		history:        history,
+		rooms:          rooms,
+		resumeSessions: resumeSessions,
+		metrics:        metrics,
+		notifier:       notifier,
+		ipRateLimits:   newIPRateLimits(config.RateLimit),
+	}
+}
+
+// identify returns client of previous connection for valid resume token, otherwise authenticates request, client ID
+// must not be reserved for bots.
+func (c *connector) identify(request *http.Request) (identity, error) {
+	if resumeToken := request.URL.Query().Get(ResumeQueryParam); resumeToken != "" {
+		if clientID, credentialExpireAt, found := c.resumeSessions.resume(resumeToken); found {
+			return identity{clientID: clientID, credentialExpireAt: credentialExpireAt}, nil
+		}
+	}
+
+	var client identity
+	var err error
+	if authenticator, ok := c.authenticator.(ExpiringAuthenticator); ok {
+		client.clientID, client.credentialExpireAt, err = authenticator.AuthenticateUntil(request)
+	} else {
+		client.clientID, err = c.authenticator.Authenticate(request)
+	}
+	if err != nil {
+		return identity{}, fmt.Errorf("authenticator.Authenticate: %w", err)
+	}
+	if isBotID(client.clientID) {
+		return identity{}, fmt.Errorf("%w: %s", errReservedClientID, client.clientID)
+	}
+
+	return client, nil
+}
+
+// connect starts oneToOneHandler for new connection of client with negotiated protocol. Connection is rate limited
+// with limits of connection and of remote IP.
+func (c *connector) connect(ctx context.Context, client identity, remoteIP, protocol string) *connection {
+	codec, _ := codecOf(protocol)
+	resumeToken := c.resumeSessions.open(client.clientID, client.credentialExpireAt)
+	conn := &connection{
+		connector: c,
+		clientID:  client.clientID,
+		readCh:    make(chan frame),
+		writeCh:   make(chan frame, writeChanelBufferSizeBytes),
+		limiter:   newRateLimiter(c.config.RateLimit, c.ipRateLimits.open(remoteIP)),
+	}
+
+	messageHandler := &oneToOneHandler{
+		logger:       c.logger,
+		pubSubHub:    c.pubSubHub,
+		history:      c.history,
+		rooms:        c.rooms,
+		clientID:     client.clientID,
+		connectionID: newID(),
+		resumeToken:  resumeToken,
+		protocol:     protocol,
+		codec:        codec,
+		readCh:       conn.readCh,
+		writeCh:      conn.writeCh,
+		replyCh:      make(chan Envelope, replyChanelBufferSize),
+		typing:       typingTimers{timers: make(map[string]*time.Timer)},
+		files: fileTransfers{
+			outgoing: make(map[string]*fileTransfer),
+			incoming: make(map[string]struct{}),
+		},
+		maxMessageSize: c.config.ReadLimitPerMessage,
+		maxTextLength:  c.config.MaxTextLength,
+		backpressure:   c.config.Backpressure,
+		metrics:        c.metrics,
+		notifier:       c.notifier,
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	conn.ctx = ctx
+	go messageHandler.write(ctx, cancel)
+	go messageHandler.read(ctx, cancel)
+	messageHandler.notify(ctx, EventClientConnected)
+	go func() {
+		<-ctx.Done()
+		messageHandler.notify(context.WithoutCancel(ctx), EventClientDisconnected)
+		c.resumeSessions.close(resumeToken)
+		c.ipRateLimits.close(remoteIP)
+	}()
+
+	return conn
+}
+
+// sendText sends text message of client without connection, e.g. from HTTP API, the same way as text message from
+// connection of client.
+func (c *connector) sendText(ctx context.Context, clientID, to, text string) (Envelope, error) {
+	messageHandler := &oneToOneHandler{
+		logger:       c.logger,
+		pubSubHub:    c.pubSubHub,
+		history:      c.history,
+		clientID:     clientID,
+		connectionID: newID(),
+		notifier:     c.notifier,
+	}
+	envelope := messageHandler.newEnvelope(messageTypeText, to, "", text)
+
+	return envelope, messageHandler.sendText(ctx, envelope)
+}
+
+// rateLimit checks message of client against limiter. Message over limit is marked as rate limited, with
+// RateLimitActionDrop its data is dropped, with RateLimitActionDisconnect errRateLimitExceeded is returned.
+func (c *connector) rateLimit(ctx context.Context, clientID string, limiter *rateLimiter, message frame) (frame, error) {
+	scope := limiter.allow(len(message.data))
+	if scope == "" {
+		return message, nil
+	}
+	c.metrics.IncRateLimited(scope, c.config.RateLimit.Action)
+	c.logger.WarnfContext(ctx, "chat, connector, rateLimit, msg: rate limit exceeded, clientID: %s, scope: %s",
+		clientID, scope)
+
+	switch c.config.RateLimit.Action {
+	case RateLimitActionDisconnect:
+		return message, errRateLimitExceeded
+	case RateLimitActionDrop:
+		message.data = nil
+	}
+	message.rateLimited = true
+
+	return message, nil
+}
+
+// read passes message from client to oneToOneHandler, checking rate limits. Dropped reports that message is over rate
+// limit and its data is dropped. Error means client must be disconnected: errRateLimitExceeded or errConnectionClosed.
+func (c *connection) read(ctx context.Context, message frame) (bool, error) {
+	message, err := c.connector.rateLimit(ctx, c.clientID, c.limiter, message)
+	if err != nil {
+		return false, err
+	}
+	dropped := message.rateLimited && message.data == nil
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return dropped, errConnectionClosed
+	}
+
+	select {
+	case c.readCh <- message:
+		return dropped, nil
+	case <-c.ctx.Done():
+		return dropped, errConnectionClosed
+	}
+}
+
+// readMessage encodes message of client, which transport makes of its own commands, and passes it to read.
+func (c *connection) readMessage(ctx context.Context, message any) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	_, err = c.read(ctx, frame{data: data})
+
+	return err
+}
+
+// close tells oneToOneHandler, that client is gone, connection may be closed several times.
+func (c *connection) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	close(c.readCh)
+}
+
+func remoteIPOf(request *http.Request) string {
+	remoteIP, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return request.RemoteAddr
+	}
+
+	return remoteIP
+}
@@ -0,0 +1,59 @@
+package chat
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	EventClientConnected       = "client.connected"
+	EventClientDisconnected    = "client.disconnected"
+	EventMessageDelivered      = "message.delivered"
+	EventMessageDeliveryFailed = "message.delivery_failed"
+)
+
+// Event is a chat activity other services may react to. Message is delivered when PubSubHub accepts it for recipient,
+// online or queued, delivery fails when recipient is unknown or PubSubHub fails.
+type Event struct {
+	Type         string    `json:"type"`
+	ClientID     string    `json:"clientID"`
+	ConnectionID string    `json:"connectionID,omitempty"`
+	MessageID    string    `json:"messageID,omitempty"`
+	To           string    `json:"to,omitempty"`
+	Room         string    `json:"room,omitempty"`
+	Reason       string    `json:"reason,omitempty"`
+	Time         time.Time `json:"time"`
+}
+
+// Notifier is notified about chat events, Notify must not block.
+type Notifier interface {
+	Notify(ctx context.Context, event Event)
+}
+
+// notify sends event about connection of client.
+func (h *oneToOneHandler) notify(ctx context.Context, typ string) {
+	h.notifier.Notify(ctx, Event{
+		Type:         typ,
+		ClientID:     h.clientID,
+		ConnectionID: h.connectionID,
+		Time:         time.Now().UTC(),
+	})
+}
+
+// notifyDelivery sends event about message of client to recipient, delivery failed if err is not nil.
+func (h *oneToOneHandler) notifyDelivery(ctx context.Context, envelope Envelope, err error) {
+	event := Event{
+		Type:         EventMessageDelivered,
+		ClientID:     h.clientID,
+		ConnectionID: h.connectionID,
+		MessageID:    envelope.ID,
+		To:           envelope.To,
+		Room:         envelope.Room,
+		Time:         time.Now().UTC(),
+	}
+	if err != nil {
+		event.Type = EventMessageDeliveryFailed
+		event.Reason = err.Error()
+	}
+	h.notifier.Notify(ctx, event)
+}
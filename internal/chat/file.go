@@ -0,0 +1,300 @@
+package chat
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// File chunk is sent in binary frame: 2 bytes big endian header length, JSON header, chunk data.
+const fileChunkHeaderLengthSize = 2
+
+var (
+	errFileChunkFrameTooShort = errors.New("file chunk frame too short")
+	errFileChunkHeaderLength  = errors.New("wrong file chunk header length")
+	errFileSizeNotPositive    = errors.New("file size must be positive")
+)
+
+type FileOfferMessageRead struct {
+	Message
+	ID       string `json:"id"`
+	To       string `json:"to"`
+	FileID   string `json:"fileID"`
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	MimeType string `json:"mime"`
+}
+
+type FileOfferMessageWrite struct {
+	Message
+	From     string    `json:"from"`
+	FileID   string    `json:"fileID"`
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	MimeType string    `json:"mime"`
+	Time     time.Time `json:"time"`
+}
+
+type FileAnswerMessageRead struct {
+	Message
+	ID     string `json:"id"`
+	To     string `json:"to"`
+	FileID string `json:"fileID"`
+	Accept bool   `json:"accept"`
+}
+
+type FileAnswerMessageWrite struct {
+	Message
+	From   string    `json:"from"`
+	FileID string    `json:"fileID"`
+	Accept bool      `json:"accept"`
+	Time   time.Time `json:"time"`
+}
+
+// FileChunkHeaderRead is a header of binary frame with file chunk from client, SHA256 is hex of chunk data checksum.
+type FileChunkHeaderRead struct {
+	To     string `json:"to"`
+	FileID string `json:"fileID"`
+	Seq    int    `json:"seq"`
+	SHA256 string `json:"sha256"`
+}
+
+type FileChunkHeaderWrite struct {
+	Message
+	From   string `json:"from"`
+	FileID string `json:"fileID"`
+	Seq    int    `json:"seq"`
+	SHA256 string `json:"sha256"`
+}
+
+// FileEnvelope is a file transfer part of Envelope.
+type FileEnvelope struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	MimeType string `json:"mime,omitempty"`
+	Accept   bool   `json:"accept,omitempty"`
+	Seq      int    `json:"seq,omitempty"`
+	SHA256   string `json:"sha256,omitempty"`
+	Data     []byte `json:"-"`
+}
+
+type fileTransfer struct {
+	size     int64
+	accepted bool
+	nextSeq  int
+	received int64
+}
+
+// fileTransfers keeps state of client file transfers: outgoing are offered by client, incoming are offered to client.
+// Keys are peer ID and file ID.
+type fileTransfers struct {
+	mu       sync.Mutex
+	outgoing map[string]*fileTransfer
+	incoming map[string]struct{}
+}
+
+func fileTransferKey(peerID, fileID string) string {
+	return peerID + "/" + fileID
+}
+
+func (m FileOfferMessageRead) validate(_ int) error {
+	err := errors.Join(required("to", m.To), required("fileID", m.FileID))
+	if err != nil {
+		return err
+	}
+	if m.Size <= 0 {
+		return errFileSizeNotPositive
+	}
+
+	return nil
+}
+
+func (m FileAnswerMessageRead) validate(_ int) error {
+	return errors.Join(required("to", m.To), required("fileID", m.FileID))
+}
+
+func (m FileChunkHeaderRead) validate(_ int) error {
+	return errors.Join(required("to", m.To), required("fileID", m.FileID))
+}
+
+func (h *oneToOneHandler) readFileOffer(ctx context.Context, fileOfferMessageRead FileOfferMessageRead) {
+	key := fileTransferKey(fileOfferMessageRead.To, fileOfferMessageRead.FileID)
+	h.files.mu.Lock()
+	_, found := h.files.outgoing[key]
+	if !found {
+		h.files.outgoing[key] = &fileTransfer{size: fileOfferMessageRead.Size}
+	}
+	h.files.mu.Unlock()
+	if found {
+		h.replyError(ctx, errorCodeFileTransfer, fileOfferMessageRead.ID, "file already offered: "+fileOfferMessageRead.FileID)
+
+		return
+	}
+
+	envelope := h.newEnvelope(messageTypeFileOffer, fileOfferMessageRead.To, "", "")
+	envelope.Ephemeral = true
+	envelope.File = &FileEnvelope{
+		ID:       fileOfferMessageRead.FileID,
+		Name:     fileOfferMessageRead.Name,
+		Size:     fileOfferMessageRead.Size,
+		MimeType: fileOfferMessageRead.MimeType,
+	}
+	err := h.pubSubHub.Pub(ctx, fileOfferMessageRead.To, envelope)
+	if err != nil {
+		h.logError(ctx, "chat, oneToOneHandler, readFileOffer, pubSubHub.Pub", err)
+		h.files.mu.Lock()
+		delete(h.files.outgoing, key)
+		h.files.mu.Unlock()
+		if errors.Is(err, ErrSubscriberNotFound) {
+			h.replyError(ctx, errorCodeUnknownRecipient, fileOfferMessageRead.ID, "unknown recipient: "+fileOfferMessageRead.To)
+		}
+	}
+}
+
+func (h *oneToOneHandler) readFileAnswer(ctx context.Context, fileAnswerMessageRead FileAnswerMessageRead) {
+	key := fileTransferKey(fileAnswerMessageRead.To, fileAnswerMessageRead.FileID)
+	h.files.mu.Lock()
+	_, found := h.files.incoming[key]
+	delete(h.files.incoming, key)
+	h.files.mu.Unlock()
+	if !found {
+		h.replyError(ctx, errorCodeFileTransfer, fileAnswerMessageRead.ID, "unknown file offer: "+fileAnswerMessageRead.FileID)
+
+		return
+	}
+
+	envelope := h.newEnvelope(messageTypeFileAnswer, fileAnswerMessageRead.To, "", "")
+	envelope.Ephemeral = true
+	envelope.File = &FileEnvelope{
+		ID:     fileAnswerMessageRead.FileID,
+		Accept: fileAnswerMessageRead.Accept,
+	}
+	err := h.pubSubHub.Pub(ctx, fileAnswerMessageRead.To, envelope)
+	if err != nil {
+		h.logError(ctx, "chat, oneToOneHandler, readFileAnswer, pubSubHub.Pub", err)
+		if errors.Is(err, ErrSubscriberNotFound) {
+			h.replyError(ctx, errorCodeUnknownRecipient, fileAnswerMessageRead.ID, "unknown recipient: "+fileAnswerMessageRead.To)
+		}
+	}
+}
+
+// readFileChunk routes chunk of accepted transfer, chunks must go in sequence and match checksum.
+func (h *oneToOneHandler) readFileChunk(ctx context.Context, message []byte) {
+	var header FileChunkHeaderRead
+	data, err := decodeFileChunk(h.codec, message, &header)
+	if err != nil {
+		h.logError(ctx, "chat, oneToOneHandler, readFileChunk, decodeFileChunk", err)
+		h.replyError(ctx, errorCodeMalformedFrame, "", err.Error())
+
+		return
+	}
+	err = header.validate(h.maxTextLength)
+	if err != nil {
+		h.replyError(ctx, errorCodeInvalidMessage, header.FileID, err.Error())
+
+		return
+	}
+
+	checksum := sha256.Sum256(data)
+	if hex.EncodeToString(checksum[:]) != header.SHA256 {
+		h.replyError(ctx, errorCodeChecksumMismatch, header.FileID, fmt.Sprintf("checksum mismatch, seq: %d", header.Seq))
+
+		return
+	}
+
+	key := fileTransferKey(header.To, header.FileID)
+	h.files.mu.Lock()
+	transfer, found := h.files.outgoing[key]
+	valid := found && transfer.accepted && transfer.nextSeq == header.Seq &&
+		transfer.received+int64(len(data)) <= transfer.size
+	if valid {
+		transfer.nextSeq++
+		transfer.received += int64(len(data))
+		if transfer.received == transfer.size {
+			delete(h.files.outgoing, key)
+		}
+	}
+	h.files.mu.Unlock()
+	if !valid {
+		h.replyError(ctx, errorCodeFileTransfer, header.FileID, fmt.Sprintf("unexpected chunk, seq: %d", header.Seq))
+
+		return
+	}
+
+	envelope := h.newEnvelope(messageTypeFileChunk, header.To, "", "")
+	envelope.Ephemeral = true
+	envelope.File = &FileEnvelope{
+		ID:     header.FileID,
+		Seq:    header.Seq,
+		SHA256: header.SHA256,
+		Data:   data,
+	}
+	err = h.pubSubHub.Pub(ctx, header.To, envelope)
+	if err != nil {
+		h.logError(ctx, "chat, oneToOneHandler, readFileChunk, pubSubHub.Pub", err)
+		h.files.mu.Lock()
+		delete(h.files.outgoing, key)
+		h.files.mu.Unlock()
+		h.replyError(ctx, errorCodeFileTransfer, header.FileID, "transfer aborted, recipient is gone: "+header.To)
+	}
+}
+
+// observeFile tracks offers to client and answers to offers of client, before they are sent to client.
+func (h *oneToOneHandler) observeFile(envelope Envelope) {
+	h.files.mu.Lock()
+	defer h.files.mu.Unlock()
+
+	key := fileTransferKey(envelope.From, envelope.File.ID)
+	switch envelope.Typ { //nolint:exhaustive
+	case messageTypeFileOffer:
+		h.files.incoming[key] = struct{}{}
+	case messageTypeFileAnswer:
+		transfer, found := h.files.outgoing[key]
+		if !found {
+			return
+		}
+		if !envelope.File.Accept {
+			delete(h.files.outgoing, key)
+
+			return
+		}
+		transfer.accepted = true
+	}
+}
+
+func decodeFileChunk(codec codec, message []byte, header any) ([]byte, error) {
+	if len(message) < fileChunkHeaderLengthSize {
+		return nil, errFileChunkFrameTooShort
+	}
+	headerLength := int(binary.BigEndian.Uint16(message))
+	if len(message) < fileChunkHeaderLengthSize+headerLength {
+		return nil, errFileChunkHeaderLength
+	}
+
+	err := codec.unmarshal(message[fileChunkHeaderLengthSize:fileChunkHeaderLengthSize+headerLength], header)
+	if err != nil {
+		return nil, fmt.Errorf("codec.unmarshal: %w", err)
+	}
+
+	return message[fileChunkHeaderLengthSize+headerLength:], nil
+}
+
+func encodeFileChunk(codec codec, header any, data []byte) ([]byte, error) {
+	headerData, err := codec.marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("codec.marshal: %w", err)
+	}
+
+	message := make([]byte, fileChunkHeaderLengthSize, fileChunkHeaderLengthSize+len(headerData)+len(data))
+	binary.BigEndian.PutUint16(message, uint16(len(headerData))) //nolint:gosec
+	message = append(message, headerData...)
+	message = append(message, data...)
+
+	return message, nil
+}
@@ -0,0 +1,10 @@
+package chat
+
+// frame is a single message received from or sent to a client.
+type frame struct {
+	data     []byte
+	binary   bool
+	tooLarge bool
+	// rateLimited frame is over rate limit, its data is empty if it is dropped
+	rateLimited bool
+}
@@ -0,0 +1,348 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	chatv1 "github.com/dark705/go-ws-chat/api/chat/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcMessageWrite is any message to client, decoded to make gRPC envelope of it.
+type grpcMessageWrite struct {
+	Typ            messageType         `json:"type"`
+	ID             string              `json:"id"`
+	Ref            string              `json:"ref"`
+	From           string              `json:"from"`
+	To             string              `json:"to"`
+	Room           string              `json:"room"`
+	Text           string              `json:"text"`
+	Time           time.Time           `json:"time"`
+	Code           errorCode           `json:"code"`
+	ClientID       string              `json:"clientID"`
+	ResumeToken    string              `json:"resumeToken"`
+	MaxMessageSize int32               `json:"maxMessageSize"`
+	MaxTextLength  int32               `json:"maxTextLength"`
+	Protocol       string              `json:"protocol"`
+	Online         bool                `json:"online"`
+	With           string              `json:"with"`
+	Messages       []grpcMessageWrite  `json:"messages"`
+	Clients        []string            `json:"clients"`
+	Typing         bool                `json:"typing"`
+	FileID         string              `json:"fileID"`
+	Name           string              `json:"name"`
+	Size           int64               `json:"size"`
+	MimeType       string              `json:"mime"`
+	Accept         bool                `json:"accept"`
+	Seq            int32               `json:"seq"`
+	SHA256         string              `json:"sha256"`
+	MessageID      string              `json:"messageID"`
+	Edited         bool                `json:"edited"`
+	Deleted        bool                `json:"deleted"`
+	Reactions      map[string][]string `json:"reactions"`
+}
+
+type grpcHandler struct {
+	chatv1.UnimplementedChatServiceServer
+	logger    Logger
+	config    ClientConfig
+	connector *connector
+}
+
+// NewGRPCHandler serves ChatService of chat.v1 gRPC API: Chat stream carries the same messages as WebSocket, as typed
+// envelopes. Client is authenticated like WebSocket one, with token from "authorization: Bearer" metadata and resume
+// token from "resume" metadata.
+func NewGRPCHandler(logger Logger, connector *connector) *grpcHandler { //nolint:revive
+	return &grpcHandler{
+		logger:    logger,
+		config:    connector.config,
+		connector: connector,
+	}
+}
+
+func (h *grpcHandler) Chat(stream grpc.BidiStreamingServer[chatv1.Envelope, chatv1.Envelope]) error {
+	ctx := stream.Context()
+	request := grpcRequest(ctx)
+	client, err := h.connector.identify(request)
+	if err != nil {
+		h.logWarn(ctx, "chat, grpcHandler, Chat, identify", err)
+
+		return status.Error(codes.Unauthenticated, "unauthorized") //nolint:wrapcheck
+	}
+	h.logInfo(ctx, "chat, grpcHandler, Chat", "new connect, clientID: "+client.clientID)
+
+	conn := h.connector.connect(ctx, client, remoteIPOf(request), SubprotocolV1JSON)
+	go h.read(ctx, stream, conn)
+	h.write(ctx, stream, conn.writeCh)
+
+	return nil
+}
+
+// read passes envelopes from client to connection until client closes stream or Chat returns.
+func (h *grpcHandler) read(ctx context.Context, stream grpc.BidiStreamingServer[chatv1.Envelope, chatv1.Envelope],
+	conn *connection,
+) {
+	defer conn.close()
+
+	for {
+		envelope, err := stream.Recv()
+		if err != nil {
+			h.logDebug(ctx, "chat, grpcHandler, read, stream.Recv", err.Error())
+
+			return
+		}
+		read := frame{tooLarge: true}
+		if proto.Size(envelope) <= h.config.ReadLimitPerMessage {
+			read, err = grpcFrame(envelope)
+			if err != nil {
+				h.logError(ctx, "chat, grpcHandler, read, grpcFrame", err)
+
+				return
+			}
+		}
+		_, err = conn.read(ctx, read)
+		if err != nil {
+			h.logDebug(ctx, "chat, grpcHandler, read, conn.read", err.Error())
+
+			return
+		}
+	}
+}
+
+// write sends messages to client until writeCh is closed, messages are dropped after client is gone.
+func (h *grpcHandler) write(ctx context.Context, stream grpc.BidiStreamingServer[chatv1.Envelope, chatv1.Envelope],
+	writeCh chan frame,
+) {
+	var sendErr error
+	for message := range writeCh {
+		if sendErr != nil {
+			continue
+		}
+
+		envelope, err := grpcEnvelope(message)
+		if err != nil {
+			h.logError(ctx, "chat, grpcHandler, write, grpcEnvelope", err)
+
+			continue
+		}
+		sendErr = stream.Send(envelope)
+		if sendErr != nil {
+			h.logDebug(ctx, "chat, grpcHandler, write, stream.Send", sendErr.Error())
+		}
+	}
+}
+
+// grpcFrame encodes envelope from client as WebSocket message, file chunk as binary frame.
+func grpcFrame(envelope *chatv1.Envelope) (frame, error) {
+	if envelope.GetType() == chatv1.MessageType_MESSAGE_TYPE_FILE_CHUNK {
+		var fileChunkHeader FileChunkHeaderRead
+		fileChunkHeader.To = envelope.GetTo()
+		fileChunkHeader.FileID = envelope.GetFileId()
+		fileChunkHeader.Seq = int(envelope.GetSeq())
+		fileChunkHeader.SHA256 = envelope.GetSha256()
+		data, err := encodeFileChunk(jsonV1Codec{}, fileChunkHeader, envelope.GetData())
+		if err != nil {
+			return frame{}, fmt.Errorf("encodeFileChunk: %w", err)
+		}
+
+		return frame{data: data, binary: true}, nil
+	}
+
+	data, err := json.Marshal(grpcMessageRead(envelope))
+	if err != nil {
+		return frame{}, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	return frame{data: data}, nil
+}
+
+// grpcMessageRead is WebSocket message of envelope type with fields of the type only, other fields are ignored, as
+// envelope has fields of all types. Envelope of unknown type has only type and ID, client gets error about its type.
+func grpcMessageRead(envelope *chatv1.Envelope) any {
+	message := Message{Typ: messageType(envelope.GetType())}
+	switch message.Typ { //nolint:exhaustive
+	case messageTypeText:
+		return TextMessageRead{Message: message, ID: envelope.GetId(), Text: envelope.GetText(), To: envelope.GetTo()}
+	case messageTypeRoomJoin, messageTypeRoomLeave, messageTypeRoomText:
+		return RoomMessageRead{Message: message, ID: envelope.GetId(), Room: envelope.GetRoom(), Text: envelope.GetText()}
+	case messageTypeHistory:
+		return HistoryMessageRead{
+			Message: message,
+			ID:      envelope.GetId(),
+			With:    envelope.GetWith(),
+			Room:    envelope.GetRoom(),
+			Before:  envelope.GetBefore(),
+			Limit:   int(envelope.GetLimit()),
+		}
+	case messageTypeTyping:
+		return TypingMessageRead{Message: message, ID: envelope.GetId(), To: envelope.GetTo(), Typing: envelope.GetTyping()}
+	case messageTypeFileOffer:
+		return FileOfferMessageRead{
+			Message:  message,
+			ID:       envelope.GetId(),
+			To:       envelope.GetTo(),
+			FileID:   envelope.GetFileId(),
+			Name:     envelope.GetName(),
+			Size:     envelope.GetSize(),
+			MimeType: envelope.GetMime(),
+		}
+	case messageTypeFileAnswer:
+		return FileAnswerMessageRead{
+			Message: message,
+			ID:      envelope.GetId(),
+			To:      envelope.GetTo(),
+			FileID:  envelope.GetFileId(),
+			Accept:  envelope.GetAccept(),
+		}
+	case messageTypeEdit:
+		return EditMessageRead{
+			Message:   message,
+			ID:        envelope.GetId(),
+			MessageID: envelope.GetMessageId(),
+			With:      envelope.GetWith(),
+			Room:      envelope.GetRoom(),
+			Text:      envelope.GetText(),
+		}
+	case messageTypeDelete:
+		return DeleteMessageRead{
+			Message:   message,
+			ID:        envelope.GetId(),
+			MessageID: envelope.GetMessageId(),
+			With:      envelope.GetWith(),
+			Room:      envelope.GetRoom(),
+		}
+	case messageTypeReact:
+		return ReactMessageRead{
+			Message:   message,
+			ID:        envelope.GetId(),
+			MessageID: envelope.GetMessageId(),
+			With:      envelope.GetWith(),
+			Room:      envelope.GetRoom(),
+			Reaction:  envelope.GetReaction(),
+		}
+	default:
+		return TypedMessageRead{Message: message, ID: envelope.GetId()}
+	}
+}
+
+// grpcEnvelope decodes message to client, binary frame is file chunk.
+func grpcEnvelope(message frame) (*chatv1.Envelope, error) {
+	var messageWrite grpcMessageWrite
+	if message.binary {
+		data, err := decodeFileChunk(jsonV1Codec{}, message.data, &messageWrite)
+		if err != nil {
+			return nil, fmt.Errorf("decodeFileChunk: %w", err)
+		}
+		envelope := messageWrite.envelope()
+		envelope.Data = data
+
+		return envelope, nil
+	}
+
+	err := json.Unmarshal(message.data, &messageWrite)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	return messageWrite.envelope(), nil
+}
+
+func (m grpcMessageWrite) envelope() *chatv1.Envelope {
+	envelope := &chatv1.Envelope{
+		Type:           chatv1.MessageType(m.Typ), //nolint:gosec
+		Id:             m.ID,
+		Ref:            m.Ref,
+		From:           m.From,
+		To:             m.To,
+		Room:           m.Room,
+		Text:           m.Text,
+		Code:           string(m.Code),
+		ClientId:       m.ClientID,
+		ResumeToken:    m.ResumeToken,
+		MaxMessageSize: m.MaxMessageSize,
+		MaxTextLength:  m.MaxTextLength,
+		Protocol:       m.Protocol,
+		Online:         m.Online,
+		With:           m.With,
+		Clients:        m.Clients,
+		Typing:         m.Typing,
+		FileId:         m.FileID,
+		Name:           m.Name,
+		Size:           m.Size,
+		Mime:           m.MimeType,
+		Accept:         m.Accept,
+		Seq:            m.Seq,
+		Sha256:         m.SHA256,
+		MessageId:      m.MessageID,
+		Edited:         m.Edited,
+		Deleted:        m.Deleted,
+	}
+	if !m.Time.IsZero() {
+		envelope.Time = timestamppb.New(m.Time)
+	}
+	for _, message := range m.Messages {
+		envelope.Messages = append(envelope.Messages, message.envelope())
+	}
+	// reactions are sorted, as order of map is random
+	for _, reaction := range slices.Sorted(maps.Keys(m.Reactions)) {
+		envelope.Reactions = append(envelope.Reactions, &chatv1.Reaction{
+			Reaction:  reaction,
+			ClientIds: m.Reactions[reaction],
+		})
+	}
+
+	return envelope
+}
+
+// grpcRequest makes request for Authenticator and resume of client, metadata of call are headers of request, resume
+// metadata is ResumeQueryParam.
+func grpcRequest(ctx context.Context) *http.Request {
+	request := &http.Request{
+		Method: http.MethodGet,
+		URL:    &url.URL{Path: "/"},
+		Header: make(http.Header),
+	}
+	if callPeer, ok := peer.FromContext(ctx); ok {
+		request.RemoteAddr = callPeer.Addr.String()
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for key, values := range md {
+			for _, value := range values {
+				request.Header.Add(key, value)
+			}
+		}
+		if resume := md.Get(ResumeQueryParam); len(resume) > 0 {
+			request.URL.RawQuery = url.Values{ResumeQueryParam: {resume[0]}}.Encode()
+		}
+	}
+
+	return request.WithContext(ctx)
+}
+
+func (h *grpcHandler) logError(ctx context.Context, point string, err error) {
+	h.logger.ErrorfContext(ctx, "%s, error: %s", point, err)
+}
+
+func (h *grpcHandler) logWarn(ctx context.Context, point string, err error) {
+	h.logger.WarnfContext(ctx, "%s, error: %s", point, err)
+}
+
+func (h *grpcHandler) logInfo(ctx context.Context, point, msg string) {
+	h.logger.InfofContext(ctx, "%s, msg: %s", point, msg)
+}
+
+func (h *grpcHandler) logDebug(ctx context.Context, point, msg string) {
+	h.logger.DebugfContext(ctx, "%s, msg: %s", point, msg)
+}
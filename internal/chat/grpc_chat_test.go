@@ -0,0 +1,68 @@
+package chat
+
+import (
+	"testing"
+
+	chatv1 "github.com/dark705/go-ws-chat/api/chat/v1"
+)
+
+func TestGRPCFrameIgnoresFieldsOfOtherTypes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		typ     messageType
+		message validator
+	}{
+		{name: "text", typ: messageTypeText, message: &TextMessageRead{}},
+		{name: "room join", typ: messageTypeRoomJoin, message: &RoomMessageRead{}},
+		{name: "room text", typ: messageTypeRoomText, message: &RoomMessageRead{}},
+		{name: "history", typ: messageTypeHistory, message: &HistoryMessageRead{}},
+		{name: "online", typ: messageTypeOnline, message: &TypedMessageRead{}},
+		{name: "typing", typ: messageTypeTyping, message: &TypingMessageRead{}},
+		{name: "file offer", typ: messageTypeFileOffer, message: &FileOfferMessageRead{}},
+		{name: "file answer", typ: messageTypeFileAnswer, message: &FileAnswerMessageRead{}},
+		{name: "edit", typ: messageTypeEdit, message: &EditMessageRead{}},
+		{name: "delete", typ: messageTypeDelete, message: &DeleteMessageRead{}},
+		{name: "react", typ: messageTypeReact, message: &ReactMessageRead{}},
+		{name: "unknown type", typ: 99, message: &TypedMessageRead{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			// envelope has fields of all types, as clients may reuse it for several messages
+			envelope := &chatv1.Envelope{
+				Type:      chatv1.MessageType(test.typ),
+				Id:        "1",
+				To:        "bob",
+				Room:      "go",
+				Text:      "hi",
+				With:      "bob",
+				Before:    "0",
+				Limit:     10,
+				Typing:    true,
+				FileId:    "f",
+				Name:      "a.txt",
+				Size:      1,
+				Mime:      "text/plain",
+				Accept:    true,
+				MessageId: "m",
+				Reaction:  "+1",
+			}
+
+			read, err := grpcFrame(envelope)
+			if err != nil {
+				t.Fatalf("grpcFrame() error: %s", err)
+			}
+			typedMessage, err := jsonV1Codec{}.typeOf(read.data)
+			if err != nil || typedMessage.Typ != test.typ || typedMessage.ID != "1" {
+				t.Fatalf("typeOf(%s) = %+v, error: %v", read.data, typedMessage, err)
+			}
+			err = jsonV1Codec{}.unmarshal(read.data, test.message)
+			if err != nil {
+				t.Fatalf("unmarshal(%s) error: %s", read.data, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	historyDefaultLimit = 20
+	historyMaxLimit     = 100
+)
+
+// HistoryMessageRead requests messages of conversation with peer (With) or of room (Room) published before message
+// with Before ID.
+type HistoryMessageRead struct {
+	Message
+	ID     string `json:"id"`
+	With   string `json:"with"`
+	Room   string `json:"room"`
+	Before string `json:"before"`
+	Limit  int    `json:"limit"`
+}
+
+type HistoryMessageWrite struct {
+	Message
+	Ref      string             `json:"ref,omitempty"`
+	With     string             `json:"with,omitempty"`
+	Room     string             `json:"room,omitempty"`
+	Messages []TextMessageWrite `json:"messages"`
+}
+
+func (m HistoryMessageRead) validate(_ int) error {
+	if m.Room == "" {
+		return required("with", m.With)
+	}
+
+	return nil
+}
+
+func (h *oneToOneHandler) readHistory(ctx context.Context, historyMessageRead HistoryMessageRead) {
+	conversation := peerConversation(h.clientID, historyMessageRead.With)
+	if historyMessageRead.Room != "" {
+		if !h.rooms.isMember(historyMessageRead.Room, h.clientID) {
+			h.replyError(ctx, errorCodeNotRoomMember, historyMessageRead.ID, "not a member of room: "+historyMessageRead.Room)
+
+			return
+		}
+		conversation = roomConversation(historyMessageRead.Room)
+	}
+
+	limit := historyMessageRead.Limit
+	if limit <= 0 {
+		limit = historyDefaultLimit
+	}
+	limit = min(limit, historyMaxLimit)
+
+	envelopes, err := h.history.List(ctx, conversation, historyMessageRead.Before, limit)
+	if errors.Is(err, ErrMessageNotFound) {
+		h.replyError(ctx, errorCodeMessageNotFound, historyMessageRead.ID, "message not found: "+historyMessageRead.Before)
+
+		return
+	}
+	if err != nil {
+		h.logError(ctx, "chat, oneToOneHandler, readHistory, history.List", err)
+		h.replyError(ctx, errorCodeInternal, historyMessageRead.ID, "fail get history")
+
+		return
+	}
+
+	// To of history reply is a peer of conversation, reply itself is sent to client directly
+	envelope := h.newEnvelope(messageTypeHistory, historyMessageRead.With, historyMessageRead.Room, "")
+	envelope.Ref = historyMessageRead.ID
+	envelope.History = envelopes
+	h.reply(ctx, envelope)
+}
+
+func (h *oneToOneHandler) saveHistory(ctx context.Context, conversation string, envelope Envelope) {
+	err := h.history.Save(ctx, conversation, envelope)
+	if err != nil {
+		h.logError(ctx, "chat, oneToOneHandler, saveHistory, history.Save", err)
+	}
+}
+
+// peerConversation is the same for both peers. IDs with ":" are prefixed with length of the first one, so the key is
+// unambiguous, other keys are kept as is for stored history.
+func peerConversation(clientID, peerID string) string {
+	ids := []string{clientID, peerID}
+	sort.Strings(ids)
+	if strings.Contains(ids[0], ":") || strings.Contains(ids[1], ":") {
+		return "peer:" + strconv.Itoa(len(ids[0])) + ":" + ids[0] + ":" + ids[1]
+	}
+
+	return "peer:" + ids[0] + ":" + ids[1]
+}
+
+func roomConversation(room string) string {
+	return "room:" + room
+}
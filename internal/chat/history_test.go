@@ -0,0 +1,40 @@
+package chat
+
+import "testing"
+
+func TestPeerConversation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		clientID      string
+		peerID        string
+		otherClientID string
+		otherPeerID   string
+		wantSame      bool
+	}{
+		{name: "order of peers", clientID: "alice", peerID: "bob", otherClientID: "bob", otherPeerID: "alice", wantSame: true},
+		{name: "colon in second ID", clientID: "a", peerID: "b:c", otherClientID: "a:b", otherPeerID: "c"},
+		{name: "colon in both IDs", clientID: "a:b", peerID: "c:d", otherClientID: "a", otherPeerID: "b:c:d"},
+		{name: "other peer", clientID: "alice", peerID: "bob", otherClientID: "alice", otherPeerID: "carol"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			conversation := peerConversation(test.clientID, test.peerID)
+			otherConversation := peerConversation(test.otherClientID, test.otherPeerID)
+			if (conversation == otherConversation) != test.wantSame {
+				t.Fatalf("peerConversation() = %q and %q, want same: %v", conversation, otherConversation, test.wantSame)
+			}
+		})
+	}
+}
+
+func TestPeerConversationKeepsStoredKeys(t *testing.T) {
+	t.Parallel()
+
+	if conversation := peerConversation("bob", "alice"); conversation != "peer:alice:bob" {
+		t.Fatalf("peerConversation() = %q, want %q", conversation, "peer:alice:bob")
+	}
+}
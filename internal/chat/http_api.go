@@ -0,0 +1,169 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+)
+
+const (
+	HTTPAPIMessagesEndpoint     = "api/messages"
+	HTTPAPIMessagesRoutePattern = http.MethodPost + " /" + HTTPAPIMessagesEndpoint
+)
+
+// APIMessageRequest is a text message to online client To, sender is a client ID authenticated by request.
+type APIMessageRequest struct {
+	To   string `json:"to"`
+	Text string `json:"text"`
+}
+
+// APIMessageResponse has ID and Time of accepted message, the same as recipient gets.
+type APIMessageResponse struct {
+	ID   string    `json:"id"`
+	Time time.Time `json:"time"`
+}
+
+func (m APIMessageRequest) validate(maxTextLength int) error {
+	err := required("to", m.To)
+	if err != nil {
+		return err
+	}
+
+	return validText(m.Text, maxTextLength)
+}
+
+type httpAPIHandler struct {
+	logger           Logger
+	authenticator    Authenticator
+	config           ClientConfig
+	pubSubHub        PubSubHub
+	connector        *connector
+	senderRateLimits *ipRateLimits // connection limits of sender, shared by its requests
+}
+
+// NewHTTPAPIHandler lets backend services send text messages to clients over HTTP, request body is limited like
+// WebSocket message with ReadLimitPerMessage and text with MaxTextLength. Requests are rate limited like messages of
+// connection: connection limits are shared by requests of the same sender, IP limits with other connections of IP.
+// Handler is not served with anonymous Authenticator, as its sender gets new random client ID with each request.
+func NewHTTPAPIHandler(logger Logger, connector *connector) *httpAPIHandler { //nolint:revive
+	config := connector.config
+
+	return &httpAPIHandler{
+		logger:        logger,
+		authenticator: connector.authenticator,
+		config:        config,
+		pubSubHub:     connector.pubSubHub,
+		connector:     connector,
+		senderRateLimits: newIPRateLimits(RateLimitConfig{
+			IPMessagesPerSecond: config.RateLimit.ConnectionMessagesPerSecond,
+			IPBytesPerSecond:    config.RateLimit.ConnectionBytesPerSecond,
+		}),
+	}
+}
+
+func (h *httpAPIHandler) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	clientID, err := h.authenticator.Authenticate(request)
+	if err == nil && isBotID(clientID) {
+		err = fmt.Errorf("%w: %s", errReservedClientID, clientID)
+	}
+	if err != nil {
+		h.logWarn(ctx, request, "chat, httpAPIHandler, authenticator.Authenticate", err)
+		http.Error(responseWriter, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(responseWriter, request.Body, int64(h.config.ReadLimitPerMessage)))
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			http.Error(responseWriter, "message too large", http.StatusRequestEntityTooLarge)
+
+			return
+		}
+		h.logWarn(ctx, request, "chat, httpAPIHandler, io.ReadAll", err)
+		http.Error(responseWriter, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	if !h.allow(ctx, clientID, remoteIPOf(request), body) {
+		http.Error(responseWriter, errRateLimitExceeded.Error(), http.StatusTooManyRequests)
+
+		return
+	}
+
+	var messageRequest APIMessageRequest
+	err = unmarshalStrict(body, &messageRequest)
+	if err == nil {
+		err = messageRequest.validate(h.config.MaxTextLength)
+	}
+	if err != nil {
+		http.Error(responseWriter, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	online, err := h.pubSubHub.Online(ctx)
+	if err != nil {
+		h.logError(ctx, request, "chat, httpAPIHandler, pubSubHub.Online", err)
+		http.Error(responseWriter, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+		return
+	}
+	if !slices.Contains(online, messageRequest.To) {
+		http.Error(responseWriter, "recipient is offline: "+messageRequest.To, http.StatusNotFound)
+
+		return
+	}
+
+	envelope, err := h.connector.sendText(ctx, clientID, messageRequest.To, messageRequest.Text)
+	if err != nil {
+		h.logError(ctx, request, "chat, httpAPIHandler, connector.sendText", err)
+		if errors.Is(err, ErrSubscriberNotFound) {
+			http.Error(responseWriter, "recipient is offline: "+messageRequest.To, http.StatusNotFound)
+
+			return
+		}
+		http.Error(responseWriter, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+		return
+	}
+
+	responseWriter.Header().Set("Content-Type", "application/json")
+	responseWriter.WriteHeader(http.StatusAccepted)
+	err = json.NewEncoder(responseWriter).Encode(APIMessageResponse{ID: envelope.ID, Time: envelope.Time})
+	if err != nil {
+		h.logError(ctx, request, "chat, httpAPIHandler, json.Encoder.Encode", err)
+	}
+}
+
+// allow checks request of sender against rate limits, request over limit is rejected unless action is warn.
+func (h *httpAPIHandler) allow(ctx context.Context, clientID, remoteIP string, body []byte) bool {
+	sender := h.senderRateLimits.open(clientID)
+	defer h.senderRateLimits.close(clientID)
+	limiter := &rateLimiter{
+		messages: sender.messages,
+		bytes:    sender.bytes,
+		ip:       h.connector.ipRateLimits.open(remoteIP),
+	}
+	defer h.connector.ipRateLimits.close(remoteIP)
+
+	message, err := h.connector.rateLimit(ctx, clientID, limiter, frame{data: body})
+
+	return err == nil && (!message.rateLimited || message.data != nil)
+}
+
+func (h *httpAPIHandler) logError(ctx context.Context, _ *http.Request, point string, err error) {
+	h.logger.ErrorfContext(ctx, "%s, error: %s", point, err)
+}
+
+func (h *httpAPIHandler) logWarn(ctx context.Context, _ *http.Request, point string, err error) {
+	h.logger.WarnfContext(ctx, "%s, error: %s", point, err)
+}
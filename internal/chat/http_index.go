@@ -29,13 +29,45 @@ func NewHTTPIndexHandler(logger Logger) *httpIndexHandler { //nolint:revive
 func (h *httpIndexHandler) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 	err := h.tpl.Execute(responseWriter, struct {
-		WSUrl               string
-		MessageTypeSettings messageType
-		MessageTypeText     messageType
+		WSUrl                 string
+		WSSubprotocol         string
+		TokenQueryParam       string
+		ResumeQueryParam      string
+		MessageTypeSettings   messageType
+		MessageTypeText       messageType
+		MessageTypeRoomJoin   messageType
+		MessageTypeRoomLeave  messageType
+		MessageTypeRoomText   messageType
+		MessageTypeError      messageType
+		MessageTypeHistory    messageType
+		MessageTypeOnline     messageType
+		MessageTypePresence   messageType
+		MessageTypeTyping     messageType
+		MessageTypeFileOffer  messageType
+		MessageTypeFileAnswer messageType
+		MessageTypeEdit       messageType
+		MessageTypeDelete     messageType
+		MessageTypeReact      messageType
 	}{
-		WSUrl:               HTTPWebSocketEndpoint,
-		MessageTypeSettings: messageTypeSettings,
-		MessageTypeText:     messageTypeText,
+		WSUrl:                 HTTPWebSocketEndpoint,
+		WSSubprotocol:         SubprotocolV1JSON,
+		TokenQueryParam:       TokenQueryParam,
+		ResumeQueryParam:      ResumeQueryParam,
+		MessageTypeSettings:   messageTypeSettings,
+		MessageTypeText:       messageTypeText,
+		MessageTypeRoomJoin:   messageTypeRoomJoin,
+		MessageTypeRoomLeave:  messageTypeRoomLeave,
+		MessageTypeRoomText:   messageTypeRoomText,
+		MessageTypeError:      messageTypeError,
+		MessageTypeHistory:    messageTypeHistory,
+		MessageTypeOnline:     messageTypeOnline,
+		MessageTypePresence:   messageTypePresence,
+		MessageTypeTyping:     messageTypeTyping,
+		MessageTypeFileOffer:  messageTypeFileOffer,
+		MessageTypeFileAnswer: messageTypeFileAnswer,
+		MessageTypeEdit:       messageTypeEdit,
+		MessageTypeDelete:     messageTypeDelete,
+		MessageTypeReact:      messageTypeReact,
 	})
 	if err != nil {
 		h.logError(ctx, request, "chat, httpIndexHandler, tpl.Execute", err)
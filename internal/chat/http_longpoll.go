@@ -0,0 +1,202 @@
+package chat
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	HTTPLongPollEndpoint         = "poll"
+	HTTPLongPollOpenRoutePattern = http.MethodPost + " /" + HTTPLongPollEndpoint
+	HTTPLongPollRoutePattern     = http.MethodGet + " /" + HTTPLongPollEndpoint
+	HTTPLongPollSendEndpoint     = "poll/send"
+	HTTPLongPollSendRoutePattern = http.MethodPost + " /" + HTTPLongPollSendEndpoint
+
+	// CursorQueryParam is a query parameter of poll request with cursor from LongPollCursorHeader of previous response.
+	CursorQueryParam = "cursor"
+	// LongPollCursorHeader is a header of poll response with cursor, which acknowledges messages of response.
+	LongPollCursorHeader = "X-Chat-Cursor"
+
+	longPollMaxMessages = 100
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+type LongPollConfig struct {
+	// TimeoutSeconds is a max time poll request waits for messages
+	TimeoutSeconds int
+	// SessionIdleSeconds is a time session is kept without poll requests
+	SessionIdleSeconds int
+}
+
+// LongPollSession is a response to open request.
+type LongPollSession struct {
+	Session string `json:"session"`
+}
+
+type longPollHandler struct {
+	logger   Logger
+	config   LongPollConfig
+	sessions *httpSessions
+}
+
+// NewLongPollHandler serves clients, which speak only plain HTTP requests, with long polling: POST opens session, GET
+// waits for messages to client, POST to send endpoint sends message from client.
+func NewLongPollHandler(logger Logger,
+	config LongPollConfig,
+	connector *connector) *longPollHandler { //nolint:revive
+	return &longPollHandler{
+		logger:   logger,
+		config:   config,
+		sessions: newHTTPSessions(logger, connector),
+	}
+}
+
+func (h *longPollHandler) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
+	switch {
+	case request.Method == http.MethodGet:
+		h.poll(responseWriter, request)
+	case request.URL.Path == "/"+HTTPLongPollSendEndpoint:
+		h.sessions.send(responseWriter, request)
+	default:
+		h.open(responseWriter, request)
+	}
+}
+
+// open starts session, which is closed when it is not polled for SessionIdleSeconds.
+func (h *longPollHandler) open(responseWriter http.ResponseWriter, request *http.Request) {
+	session, ok := h.sessions.open(responseWriter, request)
+	if !ok {
+		return
+	}
+	session.idle = time.AfterFunc(h.idleTimeout(), func() {
+		h.logDebug(context.Background(), "chat, longPollHandler, open", "session is idle: "+session.id)
+		h.sessions.close(session)
+	})
+
+	h.writeJSON(request.Context(), responseWriter, http.StatusOK, LongPollSession{Session: session.id})
+}
+
+// poll responds with JSON array of messages to client, as soon as there is at least one or with empty array after
+// TimeoutSeconds. Binary frames are base64 strings in array. The first message of session is settings message.
+// Messages are acknowledged with cursor of response in the next poll request, not acknowledged ones are sent again.
+func (h *longPollHandler) poll(responseWriter http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	session, found := h.sessions.get(request.URL.Query().Get(SessionQueryParam))
+	if !found {
+		http.Error(responseWriter, errSessionNotFound.Error(), http.StatusNotFound)
+
+		return
+	}
+	if !session.polling.CompareAndSwap(false, true) {
+		http.Error(responseWriter, "session is already polled", http.StatusConflict)
+
+		return
+	}
+	session.idle.Stop()
+	defer func() {
+		session.idle.Reset(h.idleTimeout())
+		session.polling.Store(false)
+	}()
+
+	err := session.ack(request.URL.Query().Get(CursorQueryParam))
+	if err != nil {
+		http.Error(responseWriter, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if len(session.unacked) == 0 {
+		timer := time.NewTimer(time.Duration(h.config.TimeoutSeconds) * time.Second)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case message, ok := <-session.writeCh:
+			if !ok {
+				h.sessions.close(session)
+				http.Error(responseWriter, errSessionNotFound.Error(), http.StatusNotFound)
+
+				return
+			}
+			session.unacked = append(session.unacked, message)
+		}
+	}
+	if len(session.unacked) > 0 {
+	drain:
+		for len(session.unacked) < longPollMaxMessages {
+			select {
+			case message, ok := <-session.writeCh:
+				if !ok {
+					h.sessions.close(session)
+
+					break drain
+				}
+				session.unacked = append(session.unacked, message)
+			default:
+				break drain
+			}
+		}
+	}
+
+	messages := make([]any, 0, len(session.unacked))
+	for _, message := range session.unacked {
+		messages = append(messages, pollMessage(message))
+	}
+	responseWriter.Header().Set(LongPollCursorHeader, strconv.Itoa(session.cursor+len(session.unacked)))
+	h.writeJSON(ctx, responseWriter, http.StatusOK, messages)
+}
+
+// ack acknowledges messages of session before cursor, empty cursor acknowledges all messages of previous responses.
+func (s *httpSession) ack(cursor string) error {
+	acked := len(s.unacked)
+	if cursor != "" {
+		position, err := strconv.Atoi(cursor)
+		if err != nil || position < s.cursor || position > s.cursor+len(s.unacked) {
+			return fmt.Errorf("%w: %s", errInvalidCursor, cursor)
+		}
+		acked = position - s.cursor
+	}
+	s.unacked = s.unacked[acked:]
+	s.cursor += acked
+
+	return nil
+}
+
+func (h *longPollHandler) idleTimeout() time.Duration {
+	return time.Duration(h.config.SessionIdleSeconds) * time.Second
+}
+
+func (h *longPollHandler) writeJSON(ctx context.Context, responseWriter http.ResponseWriter, status int, body any) {
+	responseWriter.Header().Set("Content-Type", "application/json")
+	responseWriter.WriteHeader(status)
+	err := json.NewEncoder(responseWriter).Encode(body)
+	if err != nil {
+		h.logError(ctx, "chat, longPollHandler, writeJSON, json.Encoder.Encode", err)
+	}
+}
+
+// pollMessage is encoded message to client as is, binary frame as base64 string.
+func pollMessage(message frame) any {
+	if message.binary {
+		return base64.StdEncoding.EncodeToString(message.data)
+	}
+
+	return json.RawMessage(message.data)
+}
+
+func (h *longPollHandler) logError(ctx context.Context, point string, err error) {
+	h.logger.ErrorfContext(ctx, "%s, error: %s", point, err)
+}
+
+func (h *longPollHandler) logDebug(ctx context.Context, point, msg string) {
+	h.logger.DebugfContext(ctx, "%s, msg: %s", point, msg)
+}
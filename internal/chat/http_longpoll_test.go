@@ -0,0 +1,43 @@
+package chat
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHTTPSessionAck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		cursor      string
+		wantErr     error
+		wantUnacked int
+		wantCursor  int
+	}{
+		{name: "all of previous response", cursor: "", wantUnacked: 0, wantCursor: 13},
+		{name: "all with cursor", cursor: "13", wantUnacked: 0, wantCursor: 13},
+		{name: "part", cursor: "11", wantUnacked: 2, wantCursor: 11},
+		{name: "none", cursor: "10", wantUnacked: 3, wantCursor: 10},
+		{name: "acknowledged before", cursor: "9", wantErr: errInvalidCursor, wantUnacked: 3, wantCursor: 10},
+		{name: "not sent yet", cursor: "14", wantErr: errInvalidCursor, wantUnacked: 3, wantCursor: 10},
+		{name: "not number", cursor: "x", wantErr: errInvalidCursor, wantUnacked: 3, wantCursor: 10},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			session := &httpSession{unacked: []frame{{data: []byte("1")}, {data: []byte("2")}, {data: []byte("3")}}}
+			session.cursor = 10
+
+			err := session.ack(test.cursor)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("ack(%q) error = %v, want %v", test.cursor, err, test.wantErr)
+			}
+			if len(session.unacked) != test.wantUnacked || session.cursor != test.wantCursor {
+				t.Fatalf("ack(%q): unacked = %d, cursor = %d, want %d, %d",
+					test.cursor, len(session.unacked), session.cursor, test.wantUnacked, test.wantCursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// SessionQueryParam is a query parameter with ID of HTTP session, messages from client are sent with it.
+	SessionQueryParam = "session"
+	// ProtocolQueryParam selects protocol of HTTP session, the same as WebSocket subprotocol. Default: SubprotocolV1JSON.
+	ProtocolQueryParam = "protocol"
+
+	binaryContentType = "application/octet-stream"
+)
+
+var errSessionNotFound = errors.New("session not found")
+
+// httpSession is a connection of client made of plain HTTP requests. Transport delivers messages to client from
+// writeCh of connection, messages from client come with separate requests.
+type httpSession struct {
+	*connection
+	id string
+	// idle closes session of long polling, when it is not polled, polling is set while poll request waits
+	idle    *time.Timer
+	polling atomic.Bool
+	// unacked are messages of poll responses not acknowledged by client yet, cursor is a number of acknowledged
+	// messages of session, they are changed only by poll request
+	unacked []frame
+	cursor  int
+}
+
+// httpSessions are HTTP sessions of clients, which are found by ID in requests.
+type httpSessions struct {
+	logger    Logger
+	connector *connector
+	mu        sync.Mutex
+	sessions  map[string]*httpSession
+}
+
+func newHTTPSessions(logger Logger, connector *connector) *httpSessions {
+	return &httpSessions{
+		logger:    logger,
+		connector: connector,
+		sessions:  make(map[string]*httpSession),
+	}
+}
+
+// open identifies client and starts new session, error is written to responseWriter.
+func (s *httpSessions) open(responseWriter http.ResponseWriter, request *http.Request) (*httpSession, bool) {
+	ctx := request.Context()
+	client, err := s.connector.identify(request)
+	if err != nil {
+		s.logWarn(ctx, "chat, httpSessions, open, identify", err)
+		http.Error(responseWriter, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+		return nil, false
+	}
+
+	protocol := request.URL.Query().Get(ProtocolQueryParam)
+	if protocol == "" {
+		protocol = SubprotocolV1JSON
+	}
+	if _, found := codecOf(protocol); !found {
+		s.logWarn(ctx, "chat, httpSessions, open, codecOf", fmt.Errorf("%w: %s", errUnsupportedSubprotocol, protocol))
+		http.Error(responseWriter, errUnsupportedSubprotocol.Error(), http.StatusBadRequest)
+
+		return nil, false
+	}
+
+	session := &httpSession{
+		connection: s.connector.connect(ctx, client, remoteIPOf(request), protocol),
+		id:         newID(),
+	}
+
+	s.mu.Lock()
+	s.sessions[session.id] = session
+	s.mu.Unlock()
+	s.logInfo(ctx, "chat, httpSessions, open", "new session, clientID: "+client.clientID+", protocol: "+protocol)
+
+	return session, true
+}
+
+func (s *httpSessions) get(id string) (*httpSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, found := s.sessions[id]
+
+	return session, found
+}
+
+// close forgets session and stops its oneToOneHandler, session may be closed several times.
+func (s *httpSessions) close(session *httpSession) {
+	s.mu.Lock()
+	delete(s.sessions, session.id)
+	s.mu.Unlock()
+
+	session.connection.close()
+	if session.idle != nil {
+		session.idle.Stop()
+	}
+}
+
+// send passes message from request body to session in SessionQueryParam, body with binaryContentType is a binary
+// frame. Responses: 202 - message is accepted, errors of message itself come to client like WebSocket ones, 404 -
+// session not found, 413 - message is larger than ReadLimitPerMessage, 429 - rate limit exceeded.
+func (s *httpSessions) send(responseWriter http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	config := s.connector.config
+	session, found := s.get(request.URL.Query().Get(SessionQueryParam))
+	if !found {
+		http.Error(responseWriter, errSessionNotFound.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(responseWriter, request.Body, int64(config.ReadLimitPerMessage)))
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			http.Error(responseWriter, "message too large", http.StatusRequestEntityTooLarge)
+
+			return
+		}
+		s.logWarn(ctx, "chat, httpSessions, send, io.ReadAll", err)
+		http.Error(responseWriter, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	message := frame{data: data, binary: request.Header.Get("Content-Type") == binaryContentType}
+	dropped, err := session.read(ctx, message)
+	switch {
+	case errors.Is(err, errRateLimitExceeded):
+		s.close(session)
+		http.Error(responseWriter, err.Error(), http.StatusTooManyRequests)
+	case err != nil:
+		http.Error(responseWriter, errSessionNotFound.Error(), http.StatusNotFound)
+	case dropped:
+		http.Error(responseWriter, errRateLimitExceeded.Error(), http.StatusTooManyRequests)
+	default:
+		responseWriter.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (s *httpSessions) logWarn(ctx context.Context, point string, err error) {
+	s.logger.WarnfContext(ctx, "%s, error: %s", point, err)
+}
+
+func (s *httpSessions) logInfo(ctx context.Context, point, msg string) {
+	s.logger.InfofContext(ctx, "%s, msg: %s", point, msg)
+}
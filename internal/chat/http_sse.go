@@ -0,0 +1,126 @@
+package chat
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	HTTPSSEEndpoint         = "sse"
+	HTTPSSERoutePattern     = http.MethodGet + " /" + HTTPSSEEndpoint
+	HTTPSSESendEndpoint     = "sse/send"
+	HTTPSSESendRoutePattern = http.MethodPost + " /" + HTTPSSESendEndpoint
+
+	sseEventSession = "session"
+	sseEventBinary  = "binary"
+)
+
+var errStreamingUnsupported = errors.New("streaming unsupported")
+
+type sseHandler struct {
+	logger   Logger
+	config   ClientConfig
+	sessions *httpSessions
+}
+
+// NewSSEHandler serves clients, which can't use WebSocket, with Server-Sent Events: GET streams messages to client,
+// POST sends message from client.
+func NewSSEHandler(logger Logger, connector *connector) *sseHandler { //nolint:revive
+	return &sseHandler{
+		logger:   logger,
+		config:   connector.config,
+		sessions: newHTTPSessions(logger, connector),
+	}
+}
+
+func (h *sseHandler) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
+	if request.Method == http.MethodPost {
+		h.sessions.send(responseWriter, request)
+
+		return
+	}
+
+	h.stream(responseWriter, request)
+}
+
+// stream opens session and writes its ID in the first event "session", then messages to client as default events,
+// binary frames as base64 in events "binary". Stream is kept alive with comments every PingIntervalSeconds.
+func (h *sseHandler) stream(responseWriter http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	if _, ok := responseWriter.(http.Flusher); !ok {
+		h.logError(ctx, "chat, sseHandler, stream", errStreamingUnsupported)
+		http.Error(responseWriter, errStreamingUnsupported.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	session, ok := h.sessions.open(responseWriter, request)
+	if !ok {
+		return
+	}
+	defer h.sessions.close(session)
+
+	responseWriter.Header().Set("Content-Type", "text/event-stream")
+	responseWriter.Header().Set("Cache-Control", "no-cache")
+	responseWriter.Header().Set("X-Accel-Buffering", "no")
+	responseWriter.WriteHeader(http.StatusOK)
+
+	responseController := http.NewResponseController(responseWriter)
+	write := func(format string, args ...any) error {
+		// write deadline is not supported by all response writers, then write is not limited in time
+		_ = responseController.SetWriteDeadline(time.Now().Add(time.Duration(h.config.WriteTimeoutSeconds) * time.Second))
+		_, err := fmt.Fprintf(responseWriter, format, args...)
+		if err != nil {
+			return fmt.Errorf("fmt.Fprintf: %w", err)
+		}
+
+		return responseController.Flush() //nolint:wrapcheck
+	}
+
+	err := write("event: %s\ndata: %s\n\n", sseEventSession, session.id)
+	if err != nil {
+		h.logError(ctx, "chat, sseHandler, stream, write session", err)
+
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(h.config.PingIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			h.logDebug(ctx, "chat, sseHandler, stream", "client gone, session: "+session.id)
+
+			return
+		case <-ticker.C:
+			err = write(": ping\n\n")
+		case message, ok := <-session.writeCh:
+			if !ok {
+				return
+			}
+			if message.binary {
+				err = write("event: %s\ndata: %s\n\n", sseEventBinary, base64.StdEncoding.EncodeToString(message.data))
+			} else {
+				err = write("data: %s\n\n", message.data)
+			}
+		}
+		if err != nil {
+			h.logError(ctx, "chat, sseHandler, stream, write", err)
+
+			return
+		}
+	}
+}
+
+func (h *sseHandler) logError(ctx context.Context, point string, err error) {
+	h.logger.ErrorfContext(ctx, "%s, error: %s", point, err)
+}
+
+func (h *sseHandler) logDebug(ctx context.Context, point, msg string) {
+	h.logger.DebugfContext(ctx, "%s, msg: %s", point, msg)
+}
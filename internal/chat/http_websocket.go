@@ -2,9 +2,8 @@ package chat
 
 import (
 	"context"
-	"math/rand/v2"
+	"errors"
 	"net/http"
-	"strconv"
 
 	"github.com/gorilla/websocket"
 )
@@ -13,74 +12,113 @@ const (
 	HTTPWebSocketEndpoint     = "ws"
 	HTTPWebSocketRoutePattern = http.MethodGet + " /" + HTTPWebSocketEndpoint
 
-	maxRandomID                = 10000
-	writeChanelBufferSizeBytes = 256
+	// CompressQueryParam set to "false" disables permessage-deflate for connection.
+	CompressQueryParam = "compress"
 )
 
+var errUnsupportedSubprotocol = errors.New("unsupported subprotocol")
+
 type webSocketHandler struct {
-	logger         Logger
-	wsUpgrader     *websocket.Upgrader
-	wsClientConfig ClientConfig
-	pubSubHub      PubSubHub
+	logger     Logger
+	wsUpgrader *websocket.Upgrader
+	config     ClientConfig
+	connector  *connector
 }
 
 func NewWebSocketHandler(logger Logger,
 	webSocketUpgrader *websocket.Upgrader,
-	wsClientConfig ClientConfig,
-	pubSubHub PubSubHub) *webSocketHandler { //nolint:revive
+	connector *connector) *webSocketHandler { //nolint:revive
+	wsUpgrader := *webSocketUpgrader
+	wsUpgrader.Subprotocols = subprotocols()
+
 	return &webSocketHandler{
-		logger:         logger,
-		wsUpgrader:     webSocketUpgrader,
-		wsClientConfig: wsClientConfig,
-		pubSubHub:      pubSubHub,
+		logger:     logger,
+		wsUpgrader: &wsUpgrader,
+		config:     connector.config,
+		connector:  connector,
 	}
 }
 
 func (h *webSocketHandler) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
-	clientID := strconv.Itoa(rand.IntN(maxRandomID)) //nolint:gosec
-
-	wsConnect, err := h.wsUpgrader.Upgrade(responseWriter, request, nil)
+	client, err := h.connector.identify(request)
 	if err != nil {
-		h.logError(ctx, request, "chat, webSocketHandler, wsUpgrader.Upgrade", err) // h.wsUpgrader.Upgrade already send http error
+		h.logWarn(ctx, request, "chat, webSocketHandler, identify", err)
+		http.Error(responseWriter, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 
 		return
 	}
-	h.logInfo(ctx, request, "chat, webSocketHandler", "new connect, clientID: "+clientID)
 
-	readCh := make(chan []byte)                              // messages FROM ws client
-	writeCh := make(chan []byte, writeChanelBufferSizeBytes) // messages TO ws client
+	if !h.subprotocolSupported(request) {
+		h.logWarn(ctx, request, "chat, webSocketHandler, subprotocolSupported", errUnsupportedSubprotocol)
+		http.Error(responseWriter, errUnsupportedSubprotocol.Error(), http.StatusBadRequest)
 
-	wsClient := &webSocketClient{
-		logger:   h.logger,
-		config:   h.wsClientConfig,
-		clientID: clientID,
-		connect:  wsConnect,
-		readCh:   readCh,
-		writeCh:  writeCh,
+		return
 	}
 
-	messageHandler := &oneToOneHandler{
-		logger:    h.logger,
-		pubSubHub: h.pubSubHub,
-		clientID:  clientID,
-		readCh:    readCh,
-		writeCh:   writeCh,
+	wsUpgrader := h.wsUpgrader
+	if request.URL.Query().Get(CompressQueryParam) == "false" {
+		wsUpgraderWithoutCompression := *h.wsUpgrader
+		wsUpgraderWithoutCompression.EnableCompression = false
+		wsUpgrader = &wsUpgraderWithoutCompression
+	}
+
+	bytes := &connectionBytes{}
+	wsConnect, err := wsUpgrader.Upgrade(
+		&countingResponseWriter{ResponseWriter: responseWriter, bytes: bytes, metrics: h.connector.metrics}, request, nil)
+	if err != nil {
+		h.logError(ctx, request, "chat, webSocketHandler, wsUpgrader.Upgrade", err) // h.wsUpgrader.Upgrade already send http error
+
+		return
 	}
+	err = wsConnect.SetCompressionLevel(h.config.CompressionLevel)
+	if err != nil {
+		h.logError(ctx, request, "chat, webSocketHandler, wsConnect.SetCompressionLevel", err)
+	}
+	protocol := wsConnect.Subprotocol()
+	if protocol == "" {
+		protocol = SubprotocolV1JSON
+	}
+	h.logInfo(ctx, request, "chat, webSocketHandler", "new connect, clientID: "+client.clientID+", protocol: "+protocol)
 
 	ctx = context.WithoutCancel(ctx)
+	wsClient := &webSocketClient{
+		logger:     h.logger,
+		config:     h.config,
+		clientID:   client.clientID,
+		connect:    wsConnect,
+		connection: h.connector.connect(ctx, client, remoteIPOf(request), protocol),
+		bytes:      bytes,
+		metrics:    h.connector.metrics,
+	}
 	go wsClient.writePump(ctx)
 	go wsClient.readPump(ctx)
+}
 
-	ctx, cancel := context.WithCancel(ctx)
-	go messageHandler.write(ctx, cancel)
-	go messageHandler.read(ctx, cancel)
+// subprotocolSupported checks that client, if asks for subprotocols, accepts at least one supported.
+func (h *webSocketHandler) subprotocolSupported(request *http.Request) bool {
+	requested := websocket.Subprotocols(request)
+	if len(requested) == 0 {
+		return true
+	}
+
+	for _, subprotocol := range requested {
+		if _, found := codecOf(subprotocol); found && subprotocol != "" {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (h *webSocketHandler) logError(ctx context.Context, _ *http.Request, point string, err error) {
 	h.logger.ErrorfContext(ctx, "%s, error: %s", point, err)
 }
 
+func (h *webSocketHandler) logWarn(ctx context.Context, _ *http.Request, point string, err error) {
+	h.logger.WarnfContext(ctx, "%s, error: %s", point, err)
+}
+
 func (h *webSocketHandler) logInfo(ctx context.Context, _ *http.Request, point, msg string) {
 	h.logger.InfofContext(ctx, "%s, msg: %s", point, msg)
 }
@@ -0,0 +1,15 @@
+package chat
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+const idSizeBytes = 16
+
+func newID() string {
+	id := make([]byte, idSizeBytes)
+	_, _ = rand.Read(id) // crypto/rand.Read never returns an error
+
+	return hex.EncodeToString(id)
+}
@@ -8,3 +8,10 @@ type Logger interface {
 	WarnfContext(ctx context.Context, format string, args ...any)
 	ErrorfContext(ctx context.Context, format string, args ...any)
 }
+
+type Metrics interface {
+	AddWebSocketWireBytes(direction string, bytes int)
+	AddWebSocketPayloadBytes(direction string, bytes int)
+	IncRateLimited(scope, action string)
+	IncBackpressure(policy, result string)
+}
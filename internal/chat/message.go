@@ -2,71 +2,331 @@ package chat
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
+	"fmt"
+	"time"
 )
 
-var errFailWriteToClientChan = errors.New("fail write to client channel")
+var (
+	ErrSubscriberNotFound = errors.New("subscriber not found")
+	ErrMessageNotFound    = errors.New("message not found")
+
+	errFailWriteToClientChan = errors.New("fail write to client channel")
+)
 
 type messageType int
 
 const (
 	messageTypeSettings messageType = iota
 	messageTypeText
+	messageTypeRoomJoin
+	messageTypeRoomLeave
+	messageTypeRoomText
+	messageTypeError
+	messageTypeHistory
+	messageTypeOnline
+	messageTypePresence
+	messageTypeTyping
+	messageTypeFileOffer
+	messageTypeFileAnswer
+	messageTypeFileChunk
+	messageTypeEdit
+	messageTypeDelete
+	messageTypeReact
+)
+
+type errorCode string
+
+const (
+	errorCodeUnknownRecipient errorCode = "unknown_recipient"
+	errorCodeMalformedJSON    errorCode = "malformed_json"
+	errorCodeMessageTooLarge  errorCode = "message_too_large"
+	errorCodeRateLimited      errorCode = "rate_limited"
+	errorCodeNotRoomMember    errorCode = "not_room_member"
+	errorCodeInternal         errorCode = "internal"
+	errorCodeMalformedFrame   errorCode = "malformed_frame"
+	errorCodeChecksumMismatch errorCode = "checksum_mismatch"
+	errorCodeFileTransfer     errorCode = "file_transfer"
+	errorCodeUnknownType      errorCode = "unknown_type"
+	errorCodeInvalidMessage   errorCode = "invalid_message"
+	errorCodeMessageNotFound  errorCode = "message_not_found"
+	errorCodeForbidden        errorCode = "forbidden"
+	errorCodeMessagesDropped  errorCode = "messages_dropped"
 )
 
 type Message struct {
 	Typ messageType `json:"type"`
 }
 
+// TypedMessageRead is a common part of messages from client.
+type TypedMessageRead struct {
+	Message
+	ID string `json:"id"`
+}
+
 type SettingsMessage struct {
 	Message
-	ID string `json:"clientID"`
+	ID             string `json:"clientID"`
+	ResumeToken    string `json:"resumeToken"`
+	MaxMessageSize int    `json:"maxMessageSize"`
+	MaxTextLength  int    `json:"maxTextLength"`
+	// Protocol is negotiated subprotocol, see SubprotocolV1JSON
+	Protocol string `json:"protocol"`
 }
 
 type TextMessageWrite struct {
 	Message
-	Text string `json:"text"`
+	ID   string    `json:"id"`
+	From string    `json:"from"`
+	To   string    `json:"to,omitempty"`
+	Room string    `json:"room,omitempty"`
+	Text string    `json:"text"`
+	Time time.Time `json:"time"`
+	// Edited, Deleted and Reactions are current state of message from history
+	Edited    bool                `json:"edited,omitempty"`
+	Deleted   bool                `json:"deleted,omitempty"`
+	Reactions map[string][]string `json:"reactions,omitempty"`
 }
 
 type TextMessageRead struct {
+	Message
+	ID   string `json:"id"`
 	Text string `json:"text"`
 	To   string `json:"to"`
 }
 
+type RoomMessageRead struct {
+	Message
+	ID   string `json:"id"`
+	Room string `json:"room"`
+	Text string `json:"text"`
+}
+
+func (m TypedMessageRead) validate(_ int) error {
+	return nil
+}
+
+func (m TextMessageRead) validate(maxTextLength int) error {
+	err := required("to", m.To)
+	if err != nil {
+		return err
+	}
+
+	return validText(m.Text, maxTextLength)
+}
+
+func (m RoomMessageRead) validate(maxTextLength int) error {
+	err := required("room", m.Room)
+	if err != nil {
+		return err
+	}
+	if m.Typ == messageTypeRoomText {
+		return validText(m.Text, maxTextLength)
+	}
+
+	return nil
+}
+
+// ErrorMessage is sent back to a client, Ref is the client side ID of the failed message.
+type ErrorMessage struct {
+	Message
+	Code errorCode `json:"code"`
+	Ref  string    `json:"ref,omitempty"`
+	Text string    `json:"text"`
+	Time time.Time `json:"time"`
+}
+
+// Envelope is a message routed through PubSubHub, ID and Time are assigned by server. Ref is ID of message envelope
+// refers to: client side ID of failed message or of request, or ID of changed message for edit, delete and react.
+type Envelope struct {
+	Typ     messageType   `json:"type"`
+	ID      string        `json:"id"`
+	Ref     string        `json:"ref,omitempty"`
+	From    string        `json:"from"`
+	To      string        `json:"to,omitempty"`
+	Room    string        `json:"room,omitempty"`
+	Text    string        `json:"text,omitempty"`
+	Code    errorCode     `json:"code,omitempty"`
+	Time    time.Time     `json:"time"`
+	History []Envelope    `json:"history,omitempty"`
+	Clients []string      `json:"clients,omitempty"`
+	Online  bool          `json:"online,omitempty"`
+	Typing  bool          `json:"typing,omitempty"`
+	File    *FileEnvelope `json:"file,omitempty"`
+	// Edited, Deleted and Reactions are changes of text message made after it is sent, reactions are client IDs by
+	// reaction
+	Edited    bool                `json:"edited,omitempty"`
+	Deleted   bool                `json:"deleted,omitempty"`
+	Reactions map[string][]string `json:"reactions,omitempty"`
+	// Ephemeral envelope is not queued for offline subscriber
+	Ephemeral bool `json:"-"`
+	// Origin is ID of connection envelope is published from, Echo is a copy of envelope published to other
+	// connections of sender, it is not delivered back to Origin.
+	Origin string `json:"-"`
+	Echo   bool   `json:"-"`
+}
+
 type PubSubHub interface {
-	Sub(ctx context.Context, id string) (chan string, error)
-	Pub(ctx context.Context, id, message string) error
+	Sub(ctx context.Context, id string) (chan Envelope, error)
+	Pub(ctx context.Context, id string, envelope Envelope) error
+	Online(ctx context.Context) ([]string, error)
+	SubPresence(ctx context.Context) (chan PresenceEvent, error)
+}
+
+// HistoryStore keeps messages of conversation, List returns up to limit messages published before message with
+// beforeID (latest if empty) in order they were published. Update atomically applies update to stored message with id
+// and returns its new state, error of update is returned as is, ErrMessageNotFound if there is no such message.
+type HistoryStore interface {
+	Save(ctx context.Context, conversation string, envelope Envelope) error
+	List(ctx context.Context, conversation, beforeID string, limit int) ([]Envelope, error)
+	Update(ctx context.Context, conversation, id string, update func(envelope *Envelope) error) (Envelope, error)
 }
 
 type oneToOneHandler struct {
 	logger    Logger
 	pubSubHub PubSubHub
+	history   HistoryStore
+	rooms     *rooms
 	clientID  string
-	readCh    chan []byte
-	writeCh   chan []byte
+	// connectionID differs for connections of the same client from several devices
+	connectionID string
+	resumeToken  string
+	protocol     string
+	codec        codec
+	readCh       chan frame
+	writeCh      chan frame
+	replyCh      chan Envelope
+	// maxMessageSize is a limit of client message size, including binary file chunk frame
+	maxMessageSize int
+	maxTextLength  int
+	backpressure   BackpressureConfig
+	metrics        Metrics
+	notifier       Notifier
+	// dropped is a number of messages dropped by backpressure policy, client is not warned about yet
+	dropped int
+
+	presenceSubscribed bool
+	typing             typingTimers
+	files              fileTransfers
 }
 
 func (h *oneToOneHandler) read(ctx context.Context, cancel context.CancelFunc) {
 	defer func() {
 		cancel()
+		h.rooms.leaveAll(h.clientID, h.connectionID)
+		h.stopTyping(context.WithoutCancel(ctx))
 		h.logDebug(ctx, "chat, oneToOneHandler, read", "stopped")
 	}()
 
 	for message := range h.readCh {
-		var textMessageRead TextMessageRead
-		err := json.Unmarshal(message, &textMessageRead)
-		if err != nil {
-			h.logError(ctx, "chat, oneToOneHandler, read, json.Unmarshal", err)
+		if message.tooLarge {
+			h.replyError(ctx, errorCodeMessageTooLarge, "", "message too large")
+
+			continue
+		}
+		if message.rateLimited {
+			h.replyError(ctx, errorCodeRateLimited, "", "rate limit exceeded")
+			if len(message.data) == 0 {
+				continue
+			}
+		}
+		if message.binary {
+			h.readFileChunk(ctx, message.data)
+
+			continue
+		}
+		h.route(ctx, message.data)
+	}
+}
+
+func (h *oneToOneHandler) readText(ctx context.Context, textMessageRead TextMessageRead) {
+	envelope := h.newEnvelope(messageTypeText, textMessageRead.To, "", textMessageRead.Text)
+	err := h.sendText(ctx, envelope)
+	if err != nil {
+		h.logError(ctx, "chat, oneToOneHandler, readText, sendText", err)
+		if errors.Is(err, ErrSubscriberNotFound) {
+			h.replyError(ctx, errorCodeUnknownRecipient, textMessageRead.ID, "unknown recipient: "+textMessageRead.To)
+		}
+	}
+}
+
+// sendText publishes text envelope to recipient, with echo to other connections of client, and saves it to history.
+func (h *oneToOneHandler) sendText(ctx context.Context, envelope Envelope) error {
+	err := h.pubSubHub.Pub(ctx, envelope.To, envelope)
+	h.notifyDelivery(ctx, envelope, err)
+	if err != nil {
+		return fmt.Errorf("pubSubHub.Pub: %w", err)
+	}
+	if envelope.To != h.clientID {
+		h.pubEcho(ctx, envelope)
+	}
+	h.saveHistory(ctx, peerConversation(h.clientID, envelope.To), envelope)
+
+	return nil
+}
+
+// pubEcho publishes copy of sent envelope to other connections of client, it may have none, e.g. HTTP API sender.
+func (h *oneToOneHandler) pubEcho(ctx context.Context, envelope Envelope) {
+	envelope.Echo = true
+	err := h.pubSubHub.Pub(ctx, h.clientID, envelope)
+	if err != nil && !errors.Is(err, ErrSubscriberNotFound) {
+		h.logError(ctx, "chat, oneToOneHandler, pubEcho, pubSubHub.Pub", err)
+	}
+}
+
+func (h *oneToOneHandler) readRoom(ctx context.Context, roomMessageRead RoomMessageRead) {
+	switch roomMessageRead.Typ { //nolint:exhaustive
+	case messageTypeRoomJoin:
+		h.rooms.join(roomMessageRead.Room, h.clientID, h.connectionID)
+		h.logDebug(ctx, "chat, oneToOneHandler, readRoom", "joined room: "+roomMessageRead.Room)
+	case messageTypeRoomLeave:
+		h.rooms.leave(roomMessageRead.Room, h.clientID, h.connectionID)
+		h.logDebug(ctx, "chat, oneToOneHandler, readRoom", "left room: "+roomMessageRead.Room)
+	case messageTypeRoomText:
+		if !h.rooms.isMember(roomMessageRead.Room, h.clientID) {
+			h.replyError(ctx, errorCodeNotRoomMember, roomMessageRead.ID, "not a member of room: "+roomMessageRead.Room)
+
+			return
 		}
 
-		err = h.pubSubHub.Pub(ctx, textMessageRead.To, textMessageRead.Text)
+		envelope := h.newEnvelope(messageTypeRoomText, "", roomMessageRead.Room, roomMessageRead.Text)
+		h.pubRoom(ctx, envelope)
+		h.notifyDelivery(ctx, envelope, nil)
+		h.saveHistory(ctx, roomConversation(roomMessageRead.Room), envelope)
+	}
+}
+
+// pubRoom publishes envelope to all members of its room, to other connections of client as echo.
+func (h *oneToOneHandler) pubRoom(ctx context.Context, envelope Envelope) {
+	for _, memberID := range h.rooms.membersOf(envelope.Room) {
+		envelope.To = memberID
+		if memberID == h.clientID {
+			h.pubEcho(ctx, envelope)
+
+			continue
+		}
+		err := h.pubSubHub.Pub(ctx, memberID, envelope)
 		if err != nil {
-			h.logError(ctx, "chat, oneToOneHandler, read, pubSubHub.Pub", err)
+			h.logError(ctx, "chat, oneToOneHandler, pubRoom, pubSubHub.Pub", err)
 		}
 	}
 }
 
+func (h *oneToOneHandler) replyError(ctx context.Context, code errorCode, ref, text string) {
+	envelope := h.newEnvelope(messageTypeError, h.clientID, "", text)
+	envelope.Code = code
+	envelope.Ref = ref
+	h.reply(ctx, envelope)
+}
+
+// reply sends message to the client itself, not through PubSubHub.
+func (h *oneToOneHandler) reply(ctx context.Context, envelope Envelope) {
+	select {
+	case h.replyCh <- envelope:
+	case <-ctx.Done():
+	}
+}
+
 func (h *oneToOneHandler) write(ctx context.Context, cancel context.CancelFunc) {
 	defer func() {
 		cancel()
@@ -77,13 +337,17 @@ func (h *oneToOneHandler) write(ctx context.Context, cancel context.CancelFunc)
 	var settingsMessage SettingsMessage
 	settingsMessage.Typ = messageTypeSettings
 	settingsMessage.ID = h.clientID
-	message, err := json.Marshal(settingsMessage)
+	settingsMessage.ResumeToken = h.resumeToken
+	settingsMessage.MaxMessageSize = h.maxMessageSize
+	settingsMessage.MaxTextLength = h.maxTextLength
+	settingsMessage.Protocol = h.protocol
+	settingsData, err := h.codec.marshal(settingsMessage)
 	if err != nil {
-		h.logError(ctx, "chat, oneToOneHandler, write, json.Marshal(settingsMessage)", err)
+		h.logError(ctx, "chat, oneToOneHandler, write, codec.marshal(settingsMessage)", err)
 
 		return
 	}
-	h.writeCh <- message
+	h.writeCh <- frame{data: settingsData}
 
 	subCh, err := h.pubSubHub.Sub(ctx, h.clientID)
 	if err != nil {
@@ -92,28 +356,53 @@ func (h *oneToOneHandler) write(ctx context.Context, cancel context.CancelFunc)
 		return
 	}
 
-	for subMessage := range subCh {
-		var textMessageWrite TextMessageWrite
-		textMessageWrite.Typ = messageTypeText
-		textMessageWrite.Text = subMessage
+	for {
+		var envelope Envelope
+		var ok bool
+		select {
+		case <-h.warnDroppedRetry():
+			h.warnDropped(ctx)
+
+			continue
+		case envelope, ok = <-subCh:
+			if !ok {
+				return
+			}
+			if envelope.Echo && envelope.Origin == h.connectionID {
+				continue
+			}
+		case envelope = <-h.replyCh:
+		}
+		if envelope.File != nil {
+			h.observeFile(envelope)
+		}
 
-		message, err = json.Marshal(textMessageWrite)
+		message, err := encodeEnvelope(h.codec, envelope)
 		if err != nil {
-			h.logError(ctx, "chat, oneToOneHandler, write, json.Marshal(textMessageWrite)", err)
+			h.logError(ctx, "chat, oneToOneHandler, write, encodeEnvelope", err)
 
 			return
 		}
 
-		select {
-		case h.writeCh <- message:
-		default:
-			h.logError(ctx, "chat, oneToOneHandler, write, default", errFailWriteToClientChan)
-
+		if !h.send(ctx, message) {
 			return
 		}
 	}
 }
 
+func (h *oneToOneHandler) newEnvelope(typ messageType, to, room, text string) Envelope {
+	return Envelope{
+		Typ:    typ,
+		ID:     newID(),
+		From:   h.clientID,
+		To:     to,
+		Room:   room,
+		Text:   text,
+		Time:   time.Now().UTC(),
+		Origin: h.connectionID,
+	}
+}
+
 func (h *oneToOneHandler) logError(ctx context.Context, point string, err error) {
 	h.logger.ErrorfContext(ctx, "%s, error: %s", point, err)
 }
@@ -121,3 +410,109 @@ func (h *oneToOneHandler) logError(ctx context.Context, point string, err error)
 func (h *oneToOneHandler) logDebug(ctx context.Context, point string, msg string) {
 	h.logger.DebugfContext(ctx, "%s, msg: %s", point, msg)
 }
+
+func encodeEnvelope(codec codec, envelope Envelope) (frame, error) {
+	var message any
+
+	switch envelope.Typ { //nolint:exhaustive
+	case messageTypeFileChunk:
+		var fileChunkHeader FileChunkHeaderWrite
+		fileChunkHeader.Typ = envelope.Typ
+		fileChunkHeader.From = envelope.From
+		fileChunkHeader.FileID = envelope.File.ID
+		fileChunkHeader.Seq = envelope.File.Seq
+		fileChunkHeader.SHA256 = envelope.File.SHA256
+		data, err := encodeFileChunk(codec, fileChunkHeader, envelope.File.Data)
+		if err != nil {
+			return frame{}, fmt.Errorf("encodeFileChunk: %w", err)
+		}
+
+		return frame{data: data, binary: true}, nil
+	case messageTypeFileOffer:
+		var fileOfferMessage FileOfferMessageWrite
+		fileOfferMessage.Typ = envelope.Typ
+		fileOfferMessage.From = envelope.From
+		fileOfferMessage.FileID = envelope.File.ID
+		fileOfferMessage.Name = envelope.File.Name
+		fileOfferMessage.Size = envelope.File.Size
+		fileOfferMessage.MimeType = envelope.File.MimeType
+		fileOfferMessage.Time = envelope.Time
+		message = fileOfferMessage
+	case messageTypeFileAnswer:
+		var fileAnswerMessage FileAnswerMessageWrite
+		fileAnswerMessage.Typ = envelope.Typ
+		fileAnswerMessage.From = envelope.From
+		fileAnswerMessage.FileID = envelope.File.ID
+		fileAnswerMessage.Accept = envelope.File.Accept
+		fileAnswerMessage.Time = envelope.Time
+		message = fileAnswerMessage
+	case messageTypeEdit, messageTypeDelete, messageTypeReact:
+		message = newUpdateMessageWrite(envelope)
+	case messageTypeError:
+		var errorMessage ErrorMessage
+		errorMessage.Typ = envelope.Typ
+		errorMessage.Code = envelope.Code
+		errorMessage.Ref = envelope.Ref
+		errorMessage.Text = envelope.Text
+		errorMessage.Time = envelope.Time
+		message = errorMessage
+	case messageTypeOnline:
+		var onlineMessage OnlineMessageWrite
+		onlineMessage.Typ = envelope.Typ
+		onlineMessage.Ref = envelope.Ref
+		onlineMessage.Clients = envelope.Clients
+		message = onlineMessage
+	case messageTypePresence:
+		var presenceMessage PresenceMessageWrite
+		presenceMessage.Typ = envelope.Typ
+		presenceMessage.ClientID = envelope.From
+		presenceMessage.Online = envelope.Online
+		presenceMessage.Time = envelope.Time
+		message = presenceMessage
+	case messageTypeTyping:
+		var typingMessage TypingMessageWrite
+		typingMessage.Typ = envelope.Typ
+		typingMessage.From = envelope.From
+		typingMessage.Typing = envelope.Typing
+		typingMessage.Time = envelope.Time
+		message = typingMessage
+	case messageTypeHistory:
+		var historyMessage HistoryMessageWrite
+		historyMessage.Typ = envelope.Typ
+		historyMessage.Ref = envelope.Ref
+		historyMessage.With = envelope.To
+		historyMessage.Room = envelope.Room
+		historyMessage.Messages = make([]TextMessageWrite, 0, len(envelope.History))
+		for _, historyEnvelope := range envelope.History {
+			historyMessage.Messages = append(historyMessage.Messages, newTextMessageWrite(historyEnvelope))
+		}
+		message = historyMessage
+	default:
+		message = newTextMessageWrite(envelope)
+	}
+
+	data, err := codec.marshal(message)
+	if err != nil {
+		return frame{}, fmt.Errorf("codec.marshal: %w", err)
+	}
+
+	return frame{data: data}, nil
+}
+
+func newTextMessageWrite(envelope Envelope) TextMessageWrite {
+	var textMessageWrite TextMessageWrite
+	textMessageWrite.Typ = envelope.Typ
+	textMessageWrite.ID = envelope.ID
+	textMessageWrite.From = envelope.From
+	if envelope.Room == "" {
+		textMessageWrite.To = envelope.To
+	}
+	textMessageWrite.Room = envelope.Room
+	textMessageWrite.Text = envelope.Text
+	textMessageWrite.Time = envelope.Time
+	textMessageWrite.Edited = envelope.Edited
+	textMessageWrite.Deleted = envelope.Deleted
+	textMessageWrite.Reactions = envelope.Reactions
+
+	return textMessageWrite
+}
@@ -0,0 +1,70 @@
+package chat
+
+import (
+	"context"
+	"time"
+)
+
+// PresenceEvent is published by PubSubHub when the first subscription of client is made or the last one is gone.
+type PresenceEvent struct {
+	ClientID string
+	Online   bool
+}
+
+type OnlineMessageWrite struct {
+	Message
+	Ref     string   `json:"ref,omitempty"`
+	Clients []string `json:"clients"`
+}
+
+type PresenceMessageWrite struct {
+	Message
+	ClientID string    `json:"clientID"`
+	Online   bool      `json:"online"`
+	Time     time.Time `json:"time"`
+}
+
+// readOnline replies with IDs of clients online.
+func (h *oneToOneHandler) readOnline(ctx context.Context, typedMessage TypedMessageRead) {
+	clientIDs, err := h.pubSubHub.Online(ctx)
+	if err != nil {
+		h.logError(ctx, "chat, oneToOneHandler, readOnline, pubSubHub.Online", err)
+		h.replyError(ctx, errorCodeInternal, typedMessage.ID, "fail get online clients")
+
+		return
+	}
+
+	envelope := h.newEnvelope(messageTypeOnline, h.clientID, "", "")
+	envelope.Ref = typedMessage.ID
+	envelope.Clients = clientIDs
+	h.reply(ctx, envelope)
+}
+
+// readPresence subscribes client to presence events of other clients till the end of connection.
+func (h *oneToOneHandler) readPresence(ctx context.Context, typedMessage TypedMessageRead) {
+	if h.presenceSubscribed {
+		return
+	}
+
+	presenceCh, err := h.pubSubHub.SubPresence(ctx)
+	if err != nil {
+		h.logError(ctx, "chat, oneToOneHandler, readPresence, pubSubHub.SubPresence", err)
+		h.replyError(ctx, errorCodeInternal, typedMessage.ID, "fail subscribe presence")
+
+		return
+	}
+	h.presenceSubscribed = true
+
+	go func() {
+		for event := range presenceCh {
+			if event.ClientID == h.clientID {
+				continue
+			}
+
+			envelope := h.newEnvelope(messageTypePresence, h.clientID, "", "")
+			envelope.From = event.ClientID
+			envelope.Online = event.Online
+			h.reply(ctx, envelope)
+		}
+	}()
+}
@@ -0,0 +1,205 @@
+package chat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+const (
+	// SubprotocolV1JSON is a JSON protocol with numeric message types, used when client does not ask for subprotocol.
+	SubprotocolV1JSON = "chat.v1.json"
+	// SubprotocolV2JSON is a JSON protocol with message types as names, like "text" or "roomJoin".
+	SubprotocolV2JSON = "chat.v2.json"
+
+	messageTypeField = "type"
+)
+
+var (
+	errUnknownMessageType = errors.New("unknown message type")
+
+	// messageTypeNames are names of message types in SubprotocolV2JSON, index is messageType.
+	messageTypeNames = []string{
+		messageTypeSettings:   "settings",
+		messageTypeText:       "text",
+		messageTypeRoomJoin:   "roomJoin",
+		messageTypeRoomLeave:  "roomLeave",
+		messageTypeRoomText:   "roomText",
+		messageTypeError:      "error",
+		messageTypeHistory:    "history",
+		messageTypeOnline:     "online",
+		messageTypePresence:   "presence",
+		messageTypeTyping:     "typing",
+		messageTypeFileOffer:  "fileOffer",
+		messageTypeFileAnswer: "fileAnswer",
+		messageTypeFileChunk:  "fileChunk",
+		messageTypeEdit:       "edit",
+		messageTypeDelete:     "delete",
+		messageTypeReact:      "react",
+	}
+)
+
+// codec encodes and decodes messages of client in format of negotiated subprotocol. unmarshal rejects unknown
+// fields, typeOf decodes only common part of message to route it.
+type codec interface {
+	marshal(message any) ([]byte, error)
+	unmarshal(data []byte, message any) error
+	typeOf(data []byte) (TypedMessageRead, error)
+}
+
+// subprotocols are supported subprotocols in order of server preference.
+func subprotocols() []string {
+	return []string{SubprotocolV2JSON, SubprotocolV1JSON}
+}
+
+// codecOf returns codec of subprotocol, empty subprotocol is SubprotocolV1JSON.
+func codecOf(subprotocol string) (codec, bool) {
+	switch subprotocol {
+	case "", SubprotocolV1JSON:
+		return jsonV1Codec{}, true
+	case SubprotocolV2JSON:
+		return jsonV2Codec{}, true
+	default:
+		return nil, false
+	}
+}
+
+type jsonV1Codec struct{}
+
+func (jsonV1Codec) marshal(message any) ([]byte, error) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	return data, nil
+}
+
+func (jsonV1Codec) unmarshal(data []byte, message any) error {
+	return unmarshalStrict(data, message)
+}
+
+// typeOf of message without "type" field is messageTypeText, as text messages of first clients had no type.
+func (jsonV1Codec) typeOf(data []byte) (TypedMessageRead, error) {
+	var typedMessage struct {
+		Typ *messageType `json:"type"`
+		ID  string       `json:"id"`
+	}
+	err := json.Unmarshal(data, &typedMessage)
+	if err != nil {
+		return TypedMessageRead{}, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	typ := messageTypeText
+	if typedMessage.Typ != nil {
+		typ = *typedMessage.Typ
+	}
+
+	return TypedMessageRead{Message: Message{Typ: typ}, ID: typedMessage.ID}, nil
+}
+
+// jsonV2Codec is jsonV1Codec with message type name instead of number in "type" field.
+type jsonV2Codec struct{}
+
+func (jsonV2Codec) marshal(message any) ([]byte, error) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	typ, found := fields[messageTypeField]
+	if !found {
+		return data, nil
+	}
+
+	number, err := strconv.Atoi(string(typ))
+	if err != nil || number < 0 || number >= len(messageTypeNames) {
+		return nil, fmt.Errorf("%w: %s", errUnknownMessageType, typ)
+	}
+	fields[messageTypeField], err = json.Marshal(messageTypeNames[number])
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	data, err = json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	return data, nil
+}
+
+func (jsonV2Codec) unmarshal(data []byte, message any) error {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	if typ, found := fields[messageTypeField]; found {
+		var name string
+		err = json.Unmarshal(typ, &name)
+		if err != nil {
+			return fmt.Errorf("json.Unmarshal(type): %w", err)
+		}
+		number := messageTypeByName(name)
+		if number < 0 {
+			return fmt.Errorf("%w: %s", errUnknownMessageType, name)
+		}
+		fields[messageTypeField] = json.RawMessage(strconv.Itoa(number))
+
+		data, err = json.Marshal(fields)
+		if err != nil {
+			return fmt.Errorf("json.Marshal: %w", err)
+		}
+	}
+
+	return unmarshalStrict(data, message)
+}
+
+func (jsonV2Codec) typeOf(data []byte) (TypedMessageRead, error) {
+	var typedMessage struct {
+		Typ string `json:"type"`
+		ID  string `json:"id"`
+	}
+	err := json.Unmarshal(data, &typedMessage)
+	if err != nil {
+		return TypedMessageRead{}, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	number := messageTypeByName(typedMessage.Typ)
+	if number < 0 {
+		return TypedMessageRead{}, fmt.Errorf("%w: %s", errUnknownMessageType, typedMessage.Typ)
+	}
+
+	return TypedMessageRead{Message: Message{Typ: messageType(number)}, ID: typedMessage.ID}, nil
+}
+
+func unmarshalStrict(data []byte, message any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(message)
+	if err != nil {
+		return fmt.Errorf("json.Decoder.Decode: %w", err)
+	}
+
+	return nil
+}
+
+// messageTypeByName returns message type number of name or -1 if unknown.
+func messageTypeByName(name string) int {
+	for number, typeName := range messageTypeNames {
+		if typeName == name {
+			return number
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,94 @@
+package chat
+
+import (
+	"testing"
+)
+
+func TestJSONV1CodecTypeOf(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    string
+		want    TypedMessageRead
+		wantErr bool
+	}{
+		{
+			name: "legacy text message without type",
+			data: `{"text":"hi","to":"bob"}`,
+			want: TypedMessageRead{Message: Message{Typ: messageTypeText}},
+		},
+		{
+			name: "null type",
+			data: `{"type":null,"id":"1","text":"hi","to":"bob"}`,
+			want: TypedMessageRead{Message: Message{Typ: messageTypeText}, ID: "1"},
+		},
+		{
+			name: "explicit settings type is kept",
+			data: `{"type":0,"id":"1"}`,
+			want: TypedMessageRead{Message: Message{Typ: messageTypeSettings}, ID: "1"},
+		},
+		{
+			name: "room text",
+			data: `{"type":4,"id":"2","room":"go","text":"hi"}`,
+			want: TypedMessageRead{Message: Message{Typ: messageTypeRoomText}, ID: "2"},
+		},
+		{name: "type of wrong kind", data: `{"type":"text"}`, wantErr: true},
+		{name: "malformed", data: `{"text":`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := jsonV1Codec{}.typeOf([]byte(test.data))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("typeOf() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Fatalf("typeOf() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestJSONV1CodecLegacyTextMessage(t *testing.T) {
+	t.Parallel()
+	data := []byte(`{"text":"hi","to":"bob"}`)
+
+	typedMessage, err := jsonV1Codec{}.typeOf(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, found := routes[typedMessage.Typ]; !found || typedMessage.Typ != messageTypeText {
+		t.Fatalf("legacy message is routed as type %d", typedMessage.Typ)
+	}
+
+	var textMessageRead TextMessageRead
+	err = jsonV1Codec{}.unmarshal(data, &textMessageRead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = textMessageRead.validate(100)
+	if err != nil || textMessageRead.Text != "hi" || textMessageRead.To != "bob" {
+		t.Fatalf("legacy message = %+v, validate() = %v", textMessageRead, err)
+	}
+}
+
+func TestJSONV2CodecRoundTrip(t *testing.T) {
+	t.Parallel()
+	codec := jsonV2Codec{}
+
+	data, err := codec.marshal(TextMessageWrite{Message: Message{Typ: messageTypeText}, Text: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	typedMessage, err := codec.typeOf(data)
+	if err != nil || typedMessage.Typ != messageTypeText {
+		t.Fatalf("typeOf(%s) = %+v, %v", data, typedMessage, err)
+	}
+
+	_, err = codec.typeOf([]byte(`{"type":"nope"}`))
+	if err == nil {
+		t.Fatal("typeOf() of unknown type name must fail")
+	}
+}
@@ -0,0 +1,160 @@
+package chat
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// RateLimitActionWarn passes message over the limit and sends rate_limited error to client.
+	RateLimitActionWarn = "warn"
+	// RateLimitActionDrop skips message over the limit and sends rate_limited error to client.
+	RateLimitActionDrop = "drop"
+	// RateLimitActionDisconnect closes connection with policy violation close code.
+	RateLimitActionDisconnect = "disconnect"
+
+	rateLimitScopeConnection = "connection"
+	rateLimitScopeIP         = "ip"
+
+	// rateLimitRefillPeriod is a time bucket takes to refill, after it idle bucket is the same as new one.
+	rateLimitRefillPeriod = time.Second
+)
+
+// RateLimitConfig sets token bucket limits of messages from clients, bucket holds one second of rate, 0 rate disables
+// limit. Bytes limits must be not less than ReadLimitPerMessage, or messages of max size are never allowed.
+type RateLimitConfig struct {
+	Action                      string
+	ConnectionMessagesPerSecond int
+	ConnectionBytesPerSecond    int
+	IPMessagesPerSecond         int
+	IPBytesPerSecond            int
+}
+
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns full bucket, or nil bucket which allows everything if rate is not positive.
+func newTokenBucket(rate int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+
+	return &tokenBucket{rate: float64(rate), tokens: float64(rate), last: time.Now()}
+}
+
+func (b *tokenBucket) allow(cost int) bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = min(b.rate, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens < float64(cost) {
+		return false
+	}
+	b.tokens -= float64(cost)
+
+	return true
+}
+
+type ipRateLimit struct {
+	messages    *tokenBucket
+	bytes       *tokenBucket
+	connections int
+	closedAt    time.Time // when the last connection is closed
+}
+
+// ipRateLimits keeps buckets shared by connections from the same remote IP, while at least one is open and until
+// buckets are refilled after that, so reconnect does not reset limits.
+type ipRateLimits struct {
+	mu        sync.Mutex
+	config    RateLimitConfig
+	ips       map[string]*ipRateLimit
+	lastSweep time.Time
+}
+
+func newIPRateLimits(config RateLimitConfig) *ipRateLimits {
+	return &ipRateLimits{config: config, ips: make(map[string]*ipRateLimit), lastSweep: time.Now()}
+}
+
+func (l *ipRateLimits) open(ip string) *ipRateLimit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep()
+	limit, found := l.ips[ip]
+	if !found {
+		limit = &ipRateLimit{
+			messages: newTokenBucket(l.config.IPMessagesPerSecond),
+			bytes:    newTokenBucket(l.config.IPBytesPerSecond),
+		}
+		l.ips[ip] = limit
+	}
+	limit.connections++
+
+	return limit
+}
+
+func (l *ipRateLimits) close(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit, found := l.ips[ip]
+	if !found {
+		return
+	}
+	limit.connections--
+	if limit.connections <= 0 {
+		limit.closedAt = time.Now()
+	}
+}
+
+// sweep drops limits without connections, which buckets are refilled, not often than once per refill period.
+func (l *ipRateLimits) sweep() {
+	now := time.Now()
+	if now.Sub(l.lastSweep) < rateLimitRefillPeriod {
+		return
+	}
+	l.lastSweep = now
+
+	for ip, limit := range l.ips {
+		if limit.connections <= 0 && now.Sub(limit.closedAt) >= rateLimitRefillPeriod {
+			delete(l.ips, ip)
+		}
+	}
+}
+
+// rateLimiter checks message of connection against connection and IP limits.
+type rateLimiter struct {
+	messages *tokenBucket
+	bytes    *tokenBucket
+	ip       *ipRateLimit
+}
+
+func newRateLimiter(config RateLimitConfig, ip *ipRateLimit) *rateLimiter {
+	return &rateLimiter{
+		messages: newTokenBucket(config.ConnectionMessagesPerSecond),
+		bytes:    newTokenBucket(config.ConnectionBytesPerSecond),
+		ip:       ip,
+	}
+}
+
+// allow returns scope of exceeded limit or empty string if message of size is allowed.
+func (l *rateLimiter) allow(size int) string {
+	if !l.messages.allow(1) || !l.bytes.allow(size) {
+		return rateLimitScopeConnection
+	}
+	if !l.ip.messages.allow(1) || !l.ip.bytes.allow(size) {
+		return rateLimitScopeIP
+	}
+
+	return ""
+}
@@ -0,0 +1,88 @@
+package chat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucketAllow(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		rate  int
+		costs []int
+		want  []bool
+	}{
+		{name: "disabled", rate: 0, costs: []int{1000, 1000}, want: []bool{true, true}},
+		{name: "full bucket", rate: 3, costs: []int{1, 1, 1, 1}, want: []bool{true, true, true, false}},
+		{name: "cost of bytes", rate: 10, costs: []int{6, 5, 4}, want: []bool{true, false, true}},
+		{name: "cost over rate", rate: 10, costs: []int{11}, want: []bool{false}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			bucket := newTokenBucket(test.rate)
+			for i, cost := range test.costs {
+				if got := bucket.allow(cost); got != test.want[i] {
+					t.Fatalf("allow(%d) #%d = %v, want %v", cost, i, got, test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTokenBucketRefill(t *testing.T) {
+	t.Parallel()
+	bucket := newTokenBucket(10)
+	if !bucket.allow(10) || bucket.allow(1) {
+		t.Fatal("bucket must hold one second of rate")
+	}
+
+	bucket.last = bucket.last.Add(-500 * time.Millisecond)
+	if !bucket.allow(5) {
+		t.Fatal("bucket is not refilled after half of second")
+	}
+
+	bucket.last = bucket.last.Add(-time.Hour)
+	if bucket.allow(11) {
+		t.Fatal("bucket is refilled over its rate")
+	}
+}
+
+func TestRateLimiterAllow(t *testing.T) {
+	t.Parallel()
+	config := RateLimitConfig{ConnectionMessagesPerSecond: 2, IPMessagesPerSecond: 3}
+	ipRateLimits := newIPRateLimits(config)
+	first := newRateLimiter(config, ipRateLimits.open("10.0.0.1"))
+	second := newRateLimiter(config, ipRateLimits.open("10.0.0.1"))
+
+	for i, want := range []string{"", "", rateLimitScopeConnection} {
+		if got := first.allow(1); got != want {
+			t.Fatalf("first allow #%d = %q, want %q", i, got, want)
+		}
+	}
+	// IP bucket is shared by connections from the same IP
+	for i, want := range []string{"", rateLimitScopeIP} {
+		if got := second.allow(1); got != want {
+			t.Fatalf("second allow #%d = %q, want %q", i, got, want)
+		}
+	}
+
+	ipRateLimits.close("10.0.0.1")
+	ipRateLimits.close("10.0.0.1")
+	// reconnect does not reset limits
+	third := newRateLimiter(config, ipRateLimits.open("10.0.0.1"))
+	if got := third.allow(1); got != rateLimitScopeIP {
+		t.Fatalf("allow after reconnect = %q, want %q", got, rateLimitScopeIP)
+	}
+	ipRateLimits.close("10.0.0.1")
+
+	ipRateLimits.ips["10.0.0.1"].closedAt = time.Now().Add(-rateLimitRefillPeriod)
+	ipRateLimits.lastSweep = time.Now().Add(-rateLimitRefillPeriod)
+	ipRateLimits.open("10.0.0.2")
+	if _, found := ipRateLimits.ips["10.0.0.1"]; found {
+		t.Fatal("IP limits are kept after its buckets are refilled")
+	}
+}
@@ -0,0 +1,78 @@
+package chat
+
+import (
+	"sync"
+	"time"
+)
+
+type resumeSession struct {
+	clientID           string
+	expireAt           time.Time // zero while client is connected
+	credentialExpireAt time.Time // zero if credential of client does not expire
+}
+
+// resumeSessions maps resume token to client ID, token is valid while client is connected and during grace window
+// after disconnect, but not after credential client authenticated with expires. Token can be used once.
+type resumeSessions struct {
+	mu       sync.Mutex
+	grace    time.Duration
+	sessions map[string]resumeSession
+}
+
+func NewResumeSessions(graceSeconds int) *resumeSessions { //nolint:revive
+	return &resumeSessions{
+		grace:    time.Duration(graceSeconds) * time.Second,
+		sessions: make(map[string]resumeSession),
+	}
+}
+
+func (r *resumeSessions) open(clientID string, credentialExpireAt time.Time) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for token, session := range r.sessions {
+		if !session.expireAt.IsZero() && now.After(session.expireAt) {
+			delete(r.sessions, token)
+		}
+	}
+
+	token := newID()
+	r.sessions[token] = resumeSession{clientID: clientID, credentialExpireAt: credentialExpireAt}
+
+	return token
+}
+
+func (r *resumeSessions) close(token string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, found := r.sessions[token]
+	if !found {
+		return
+	}
+	session.expireAt = time.Now().Add(r.grace)
+	r.sessions[token] = session
+}
+
+// resume returns client ID and credential expiry of session, resumed connection keeps credential expiry, so it can not
+// be renewed past it.
+func (r *resumeSessions) resume(token string) (string, time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, found := r.sessions[token]
+	if !found {
+		return "", time.Time{}, false
+	}
+	delete(r.sessions, token)
+	now := time.Now()
+	if !session.expireAt.IsZero() && now.After(session.expireAt) {
+		return "", time.Time{}, false
+	}
+	if !session.credentialExpireAt.IsZero() && !now.Before(session.credentialExpireAt) {
+		return "", time.Time{}, false
+	}
+
+	return session.clientID, session.credentialExpireAt, true
+}
@@ -0,0 +1,54 @@
+package chat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResumeSessions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name               string
+		credentialExpireAt time.Time
+		closed             bool
+		grace              int
+		wantFound          bool
+	}{
+		{name: "connected", wantFound: true},
+		{name: "in grace window", closed: true, grace: 60, wantFound: true},
+		{name: "after grace window", closed: true, wantFound: false},
+		{name: "credential valid", credentialExpireAt: time.Now().Add(time.Hour), wantFound: true},
+		{name: "credential expired", credentialExpireAt: time.Now().Add(-time.Second), wantFound: false},
+		{
+			name:               "credential expired in grace window",
+			credentialExpireAt: time.Now().Add(-time.Second),
+			closed:             true,
+			grace:              60,
+			wantFound:          false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			sessions := NewResumeSessions(test.grace)
+			token := sessions.open("alice", test.credentialExpireAt)
+			if test.closed {
+				sessions.close(token)
+				time.Sleep(time.Millisecond)
+			}
+
+			clientID, credentialExpireAt, found := sessions.resume(token)
+			if found != test.wantFound {
+				t.Fatalf("resume() found = %v, want %v", found, test.wantFound)
+			}
+			if found && (clientID != "alice" || !credentialExpireAt.Equal(test.credentialExpireAt)) {
+				t.Fatalf("resume() = %q, %v, want alice, %v", clientID, credentialExpireAt, test.credentialExpireAt)
+			}
+			if _, _, found = sessions.resume(token); found {
+				t.Fatal("resume() token is used twice")
+			}
+		})
+	}
+}
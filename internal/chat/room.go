@@ -0,0 +1,87 @@
+package chat
+
+import (
+	"sort"
+	"sync"
+)
+
+// rooms keeps members of rooms, client is a member while at least one of its connections is joined.
+type rooms struct {
+	mu      sync.Mutex
+	members map[string]map[string]map[string]struct{} // room, client ID, connection ID
+}
+
+func NewRooms() *rooms { //nolint:revive
+	return &rooms{
+		members: make(map[string]map[string]map[string]struct{}),
+	}
+}
+
+func (r *rooms) join(room, clientID, connectionID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	members, found := r.members[room]
+	if !found {
+		members = make(map[string]map[string]struct{})
+		r.members[room] = members
+	}
+	connections, found := members[clientID]
+	if !found {
+		connections = make(map[string]struct{})
+		members[clientID] = connections
+	}
+	connections[connectionID] = struct{}{}
+}
+
+func (r *rooms) leave(room, clientID, connectionID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.leaveLocked(room, clientID, connectionID)
+}
+
+func (r *rooms) leaveAll(clientID, connectionID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for room := range r.members {
+		r.leaveLocked(room, clientID, connectionID)
+	}
+}
+
+func (r *rooms) leaveLocked(room, clientID, connectionID string) {
+	members, found := r.members[room]
+	if !found {
+		return
+	}
+	delete(members[clientID], connectionID)
+	if len(members[clientID]) == 0 {
+		delete(members, clientID)
+	}
+	if len(members) == 0 {
+		delete(r.members, room)
+	}
+}
+
+func (r *rooms) isMember(room, clientID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, found := r.members[room][clientID]
+
+	return found
+}
+
+func (r *rooms) membersOf(room string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	members := make([]string, 0, len(r.members[room]))
+	for clientID := range r.members[room] {
+		members = append(members, clientID)
+	}
+	sort.Strings(members)
+
+	return members
+}
@@ -0,0 +1,110 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+var (
+	errFieldRequired = errors.New("field is required")
+	errTextTooLong   = errors.New("text is too long")
+)
+
+// validator is implemented by messages from client, validate checks required fields and limits after decode.
+type validator interface {
+	validate(maxTextLength int) error
+}
+
+// route decodes and validates message of registered type and handles it.
+type route func(h *oneToOneHandler, ctx context.Context, typedMessage TypedMessageRead, message []byte)
+
+// routes are handlers of text messages from client by type, binary frames are always file chunks.
+var routes = map[messageType]route{ //nolint:gochecknoglobals
+	messageTypeText:       handle((*oneToOneHandler).readText),
+	messageTypeRoomJoin:   handle((*oneToOneHandler).readRoom),
+	messageTypeRoomLeave:  handle((*oneToOneHandler).readRoom),
+	messageTypeRoomText:   handle((*oneToOneHandler).readRoom),
+	messageTypeHistory:    handle((*oneToOneHandler).readHistory),
+	messageTypeOnline:     handle((*oneToOneHandler).readOnline),
+	messageTypePresence:   handle((*oneToOneHandler).readPresence),
+	messageTypeTyping:     handle((*oneToOneHandler).readTyping),
+	messageTypeFileOffer:  handle((*oneToOneHandler).readFileOffer),
+	messageTypeFileAnswer: handle((*oneToOneHandler).readFileAnswer),
+	messageTypeEdit:       handle((*oneToOneHandler).readEdit),
+	messageTypeDelete:     handle((*oneToOneHandler).readDelete),
+	messageTypeReact:      handle((*oneToOneHandler).readReact),
+}
+
+func handle[T validator](read func(h *oneToOneHandler, ctx context.Context, message T)) route {
+	return func(h *oneToOneHandler, ctx context.Context, typedMessage TypedMessageRead, data []byte) {
+		var message T
+		err := h.codec.unmarshal(data, &message)
+		if err != nil {
+			h.logDebug(ctx, "chat, oneToOneHandler, route, codec.unmarshal", err.Error())
+			h.replyError(ctx, errorCodeMalformedJSON, typedMessage.ID, err.Error())
+
+			return
+		}
+
+		err = message.validate(h.maxTextLength)
+		if err != nil {
+			h.logDebug(ctx, "chat, oneToOneHandler, route, validate", err.Error())
+			h.replyError(ctx, errorCodeInvalidMessage, typedMessage.ID, err.Error())
+
+			return
+		}
+
+		read(h, ctx, message)
+	}
+}
+
+// route finds handler of message by type.
+func (h *oneToOneHandler) route(ctx context.Context, message []byte) {
+	if !utf8.Valid(message) {
+		h.replyError(ctx, errorCodeInvalidMessage, "", "message is not valid UTF-8")
+
+		return
+	}
+
+	typedMessage, err := h.codec.typeOf(message)
+	if err != nil {
+		h.logDebug(ctx, "chat, oneToOneHandler, route, codec.typeOf", err.Error())
+		if errors.Is(err, errUnknownMessageType) {
+			h.replyError(ctx, errorCodeUnknownType, "", err.Error())
+		} else {
+			h.replyError(ctx, errorCodeMalformedJSON, "", err.Error())
+		}
+
+		return
+	}
+
+	read, found := routes[typedMessage.Typ]
+	if !found {
+		h.replyError(ctx, errorCodeUnknownType, typedMessage.ID, fmt.Sprintf("%s: %d", errUnknownMessageType, typedMessage.Typ))
+
+		return
+	}
+	read(h, ctx, typedMessage, message)
+}
+
+func required(field, value string) error {
+	if value == "" {
+		return fmt.Errorf("%w: %s", errFieldRequired, field)
+	}
+
+	return nil
+}
+
+func validText(text string, maxTextLength int) error {
+	err := required("text", text)
+	if err != nil {
+		return err
+	}
+	if utf8.RuneCountInString(text) > maxTextLength {
+		return fmt.Errorf("%w: max %d characters", errTextTooLong, maxTextLength)
+	}
+
+	return nil
+}
@@ -0,0 +1,349 @@
+package chat
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	ircServerName       = "go-ws-chat"
+	ircChannelPrefix    = "#"
+	ircChannelChars     = "#&"
+	ircUnregisteredNick = "*"
+
+	ircReplyWelcome         = "001"
+	ircReplyEndOfNames      = "366"
+	ircErrNoSuchNick        = "401"
+	ircErrUnknownCommand    = "421"
+	ircErrNoNicknameGiven   = "431"
+	ircErrNotOnChannel      = "442"
+	ircErrNotRegistered     = "451"
+	ircErrNeedMoreParams    = "461"
+	ircErrAlreadyRegistered = "462"
+	ircErrPasswordMismatch  = "464"
+)
+
+// ircMessage is a parsed IRC line: [:prefix] command params... [:trailing], trailing is the last of params.
+type ircMessage struct {
+	command string
+	params  []string
+}
+
+type ircHandler struct {
+	logger    Logger
+	config    ClientConfig
+	connector *connector
+}
+
+// NewIRCHandler serves raw TCP connections as minimal IRC server: NICK, USER, PASS, PRIVMSG, JOIN, PART, PING, PONG
+// and QUIT. Nick is a client ID: client is authenticated like WebSocket one, with token from PASS if authentication
+// mode needs it, and its nick is changed to client ID, so client can't take client ID of other one with its nick. IRC
+// channel #room is chat room "room". Server pings client every PingIntervalSeconds, connection without lines from
+// client for ReadTimeoutSeconds is closed.
+func NewIRCHandler(logger Logger, connector *connector) *ircHandler { //nolint:revive
+	return &ircHandler{
+		logger:    logger,
+		config:    connector.config,
+		connector: connector,
+	}
+}
+
+func (h *ircHandler) ServeConn(ctx context.Context, conn net.Conn) {
+	lines := newLineConn(conn, h.config)
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, h.config.ReadLimitPerMessage), h.config.ReadLimitPerMessage)
+
+	nick, client, ok := h.register(ctx, lines, scanner)
+	if !ok {
+		return
+	}
+	lines.signInUntil = time.Time{}
+	clientID := client.clientID
+	h.logInfo(ctx, "chat, ircHandler, ServeConn", "new connect, clientID: "+clientID)
+	if nick != clientID {
+		lines.write(":" + nick + " NICK " + clientID)
+	}
+
+	chatConn := h.connector.connect(ctx, client, tcpRemoteIP(conn), SubprotocolV1JSON)
+	writeDone := make(chan struct{})
+	go func() {
+		defer close(writeDone)
+		h.write(ctx, lines, clientID, chatConn.writeCh)
+	}()
+	h.read(ctx, lines, scanner, clientID, chatConn)
+	<-writeDone
+}
+
+// register waits for NICK and USER, then authenticates client with token from PASS. Client, which is not registered
+// in tcpSignInTimeout, is disconnected.
+func (h *ircHandler) register(ctx context.Context, lines *lineConn, scanner *bufio.Scanner) (string, identity, bool) {
+	var nick, password string
+	var user bool
+	for lines.scan(scanner) {
+		message := parseIRCMessage(scanner.Text())
+		switch message.command {
+		case "PASS":
+			password = message.param(0)
+		case "NICK":
+			nick = message.param(0)
+			if nick == "" {
+				lines.write(ircReply(ircErrNoNicknameGiven, ircUnregisteredNick, "No nickname given"))
+			}
+		case "USER":
+			user = true
+		case "PING":
+			lines.write(":" + ircServerName + " PONG " + ircServerName + " :" + message.param(0))
+		case "QUIT":
+			lines.write("ERROR :Closing link")
+
+			return "", identity{}, false
+		case "CAP", "PONG", "":
+		default:
+			lines.write(ircReply(ircErrNotRegistered, ircUnregisteredNick, "You have not registered"))
+		}
+		if nick == "" || !user {
+			continue
+		}
+
+		client, err := h.connector.identify(lineRequest(ctx, lines.conn, password))
+		if err != nil {
+			h.logWarn(ctx, "chat, ircHandler, register, identify", err)
+			lines.write(ircReply(ircErrPasswordMismatch, nick, "Password incorrect"))
+			lines.write("ERROR :Closing link")
+
+			return "", identity{}, false
+		}
+
+		return nick, client, true
+	}
+	if errors.Is(scanner.Err(), os.ErrDeadlineExceeded) {
+		lines.write("ERROR :Registration timeout")
+	}
+
+	return "", identity{}, false
+}
+
+// read turns IRC commands into messages of client until QUIT or connection is closed.
+func (h *ircHandler) read(ctx context.Context, lines *lineConn, scanner *bufio.Scanner, clientID string,
+	chatConn *connection,
+) {
+	defer chatConn.close()
+
+	for lines.scan(scanner) {
+		message := parseIRCMessage(scanner.Text())
+		var messages []any
+		switch message.command {
+		case "", "CAP", "PONG":
+			continue
+		case "PING":
+			lines.write(":" + ircServerName + " PONG " + ircServerName + " :" + message.param(0))
+
+			continue
+		case "QUIT":
+			lines.write("ERROR :Closing link")
+
+			return
+		case "NICK", "USER", "PASS":
+			lines.write(ircReply(ircErrAlreadyRegistered, clientID, "You may not reregister"))
+
+			continue
+		case "PRIVMSG":
+			if len(message.params) < 2 {
+				lines.write(ircReply(ircErrNeedMoreParams, clientID, "PRIVMSG :Not enough parameters"))
+
+				continue
+			}
+			for _, target := range strings.Split(message.params[0], ",") {
+				messages = append(messages, ircPrivateMessage(target, message.params[1]))
+			}
+		case "JOIN", "PART":
+			if len(message.params) < 1 {
+				lines.write(ircReply(ircErrNeedMoreParams, clientID, message.command+" :Not enough parameters"))
+
+				continue
+			}
+			for _, channel := range strings.Split(message.params[0], ",") {
+				messages = append(messages, h.ircRoomMessage(lines, clientID, message.command, channel))
+			}
+		default:
+			lines.write(ircReply(ircErrUnknownCommand, clientID, message.command+" :Unknown command"))
+
+			continue
+		}
+
+		for _, message := range messages {
+			err := chatConn.readMessage(ctx, message)
+			if errors.Is(err, errRateLimitExceeded) {
+				lines.write("ERROR :Rate limit exceeded")
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// ircRoomMessage makes room join or leave message of channel and confirms it to client, as room membership has no
+// reply.
+func (h *ircHandler) ircRoomMessage(lines *lineConn, clientID, command, channel string) RoomMessageRead {
+	var roomMessageRead RoomMessageRead
+	roomMessageRead.Typ = messageTypeRoomLeave
+	roomMessageRead.Room = strings.TrimLeft(channel, ircChannelChars)
+	if command == "JOIN" {
+		roomMessageRead.Typ = messageTypeRoomJoin
+	}
+	if roomMessageRead.Room == "" {
+		return roomMessageRead // rejected by validation
+	}
+
+	lines.write(":" + ircPrefix(clientID) + " " + command + " " + ircChannel(roomMessageRead.Room))
+	if command == "JOIN" {
+		lines.write(ircReply(ircReplyEndOfNames, clientID, ircChannel(roomMessageRead.Room)+" :End of /NAMES list"))
+	}
+
+	return roomMessageRead
+}
+
+func ircPrivateMessage(target, text string) any {
+	if strings.ContainsAny(target[:min(1, len(target))], ircChannelChars) {
+		var roomMessageRead RoomMessageRead
+		roomMessageRead.Typ = messageTypeRoomText
+		roomMessageRead.Room = strings.TrimLeft(target, ircChannelChars)
+		roomMessageRead.Text = text
+
+		return roomMessageRead
+	}
+
+	var textMessageRead TextMessageRead
+	textMessageRead.Typ = messageTypeText
+	textMessageRead.To = target
+	textMessageRead.Text = text
+
+	return textMessageRead
+}
+
+// write shows messages to client as IRC lines and pings client until writeCh is closed, then closes connection.
+func (h *ircHandler) write(ctx context.Context, lines *lineConn, clientID string, writeCh chan frame) {
+	defer lines.conn.Close() //nolint:errcheck
+
+	ticker := time.NewTicker(time.Duration(h.config.PingIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		var line string
+		select {
+		case message, ok := <-writeCh:
+			if !ok {
+				return
+			}
+			line = h.ircLine(ctx, clientID, message)
+		case <-ticker.C:
+			line = "PING :" + ircServerName
+		}
+
+		if line != "" && !lines.write(line) {
+			return
+		}
+	}
+}
+
+// ircLine is IRC line of message to client, empty for messages IRC does not show.
+func (h *ircHandler) ircLine(ctx context.Context, clientID string, message frame) string {
+	if message.binary {
+		return ""
+	}
+
+	var lineMessage lineMessageWrite
+	err := json.Unmarshal(message.data, &lineMessage)
+	if err != nil {
+		h.logError(ctx, "chat, ircHandler, ircLine, json.Unmarshal", err)
+
+		return ""
+	}
+
+	switch lineMessage.Typ { //nolint:exhaustive
+	case messageTypeSettings:
+		return ircReply(ircReplyWelcome, clientID, "Welcome to "+ircServerName+", "+clientID)
+	case messageTypeText:
+		return ":" + ircPrefix(lineMessage.From) + " PRIVMSG " + lineMessage.To + " :" + lineMessage.Text
+	case messageTypeRoomText:
+		return ":" + ircPrefix(lineMessage.From) + " PRIVMSG " + ircChannel(lineMessage.Room) + " :" + lineMessage.Text
+	case messageTypeError:
+		return ircErrorReply(clientID, lineMessage)
+	default:
+		return ""
+	}
+}
+
+// ircErrorReply is numeric reply for errors IRC has, other errors are notices.
+func ircErrorReply(clientID string, lineMessage lineMessageWrite) string {
+	switch lineMessage.Code { //nolint:exhaustive
+	case errorCodeUnknownRecipient:
+		return ircReply(ircErrNoSuchNick, clientID, strings.TrimPrefix(lineMessage.Text, "unknown recipient: ")+
+			" :No such nick/channel")
+	case errorCodeNotRoomMember:
+		return ircReply(ircErrNotOnChannel, clientID, ircChannel(strings.TrimPrefix(lineMessage.Text,
+			"not a member of room: "))+" :You're not on that channel")
+	default:
+		return ":" + ircServerName + " NOTICE " + clientID + " :" + string(lineMessage.Code) + ": " + lineMessage.Text
+	}
+}
+
+func parseIRCMessage(line string) ircMessage {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, ":") {
+		_, line, _ = strings.Cut(line, " ")
+	}
+	line, trailing, hasTrailing := strings.Cut(line, " :")
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ircMessage{}
+	}
+	if hasTrailing {
+		fields = append(fields, trailing)
+	}
+
+	return ircMessage{command: strings.ToUpper(fields[0]), params: fields[1:]}
+}
+
+func (m ircMessage) param(i int) string {
+	if i < len(m.params) {
+		return m.params[i]
+	}
+
+	return ""
+}
+
+// ircReply is numeric reply to client nick, text is the last parameter unless it has one already.
+func ircReply(numeric, nick, text string) string {
+	if strings.Contains(text, " :") {
+		return ":" + ircServerName + " " + numeric + " " + nick + " " + text
+	}
+
+	return ":" + ircServerName + " " + numeric + " " + nick + " :" + text
+}
+
+func ircPrefix(clientID string) string {
+	return clientID + "!" + clientID + "@" + ircServerName
+}
+
+func ircChannel(room string) string {
+	return ircChannelPrefix + room
+}
+
+func (h *ircHandler) logError(ctx context.Context, point string, err error) {
+	h.logger.ErrorfContext(ctx, "%s, error: %s", point, err)
+}
+
+func (h *ircHandler) logWarn(ctx context.Context, point string, err error) {
+	h.logger.WarnfContext(ctx, "%s, error: %s", point, err)
+}
+
+func (h *ircHandler) logInfo(ctx context.Context, point, msg string) {
+	h.logger.InfofContext(ctx, "%s, msg: %s", point, msg)
+}
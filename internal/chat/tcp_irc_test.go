@@ -0,0 +1,274 @@
+package chat_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/dark705/go-ws-chat/internal/auth"
+	"github.com/dark705/go-ws-chat/internal/chat"
+	"github.com/dark705/go-ws-chat/internal/history"
+	"github.com/dark705/go-ws-chat/internal/pubsub"
+	"github.com/dark705/go-ws-chat/internal/tcpserver"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) DebugfContext(context.Context, string, ...any) {}
+func (nopLogger) InfofContext(context.Context, string, ...any)  {}
+func (nopLogger) WarnfContext(context.Context, string, ...any)  {}
+func (nopLogger) ErrorfContext(context.Context, string, ...any) {}
+
+type nopMetrics struct{}
+
+func (nopMetrics) AddWebSocketWireBytes(string, int)    {}
+func (nopMetrics) AddWebSocketPayloadBytes(string, int) {}
+func (nopMetrics) IncRateLimited(string, string)        {}
+func (nopMetrics) IncBackpressure(string, string)       {}
+
+type nopNotifier struct{}
+
+func (nopNotifier) Notify(context.Context, chat.Event) {}
+
+type ircClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+type testServers struct {
+	irc       string
+	webSocket string
+	pubSubHub chat.PubSubHub
+}
+
+// startServers serves IRC and WebSocket handlers of one connector with anonymous authentication on loopback address.
+func startServers(t *testing.T) testServers {
+	t.Helper()
+	pubSubHub := newPubSubHub()
+	connector := chat.NewConnector(nopLogger{}, auth.NewAnonymous(), chat.ClientConfig{
+		WriteTimeoutSeconds: 1,
+		ReadTimeoutSeconds:  5,
+		ReadLimitPerMessage: 2048,
+		PingIntervalSeconds: 1,
+		MaxTextLength:       1000,
+		Backpressure:        chat.BackpressureConfig{Policy: chat.BackpressureDisconnect},
+	}, pubSubHub, history.NewInmemory(nopLogger{}, 10), chat.NewRooms(), chat.NewResumeSessions(60), nopMetrics{},
+		nopNotifier{})
+	webSocketServer := httptest.NewServer(chat.NewWebSocketHandler(nopLogger{}, &websocket.Upgrader{}, connector))
+	t.Cleanup(webSocketServer.Close)
+
+	return testServers{
+		irc:       serveTCP(t, chat.NewIRCHandler(nopLogger{}, connector)),
+		webSocket: "ws" + strings.TrimPrefix(webSocketServer.URL, "http"),
+		pubSubHub: pubSubHub,
+	}
+}
+
+// newPubSubHub returns in memory pub/sub hub with offline queue.
+func newPubSubHub() chat.PubSubHub {
+	return pubsub.NewInmemory(pubsub.Config{
+		OfflineQueueSize:          10,
+		OfflineQueueTTLSeconds:    60,
+		OfflineQueueMaxRecipients: 10,
+		OfflineQueueMaxBytes:      1 << 20,
+	}, nopLogger{})
+}
+
+// serveTCP serves connections of handler on loopback address and returns the address.
+func serveTCP(t *testing.T, handler tcpserver.Handler) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+		listener.Close() //nolint:errcheck
+	})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close() //nolint:errcheck
+				handler.ServeConn(ctx, conn)
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func dialIRC(t *testing.T, address string) *ircClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() }) //nolint:errcheck
+
+	return &ircClient{t: t, conn: conn, reader: bufio.NewReader(conn)}
+}
+
+func (c *ircClient) send(line string) {
+	c.t.Helper()
+	_, err := c.conn.Write([]byte(line + "\r\n"))
+	if err != nil {
+		c.t.Fatalf("send %q: %s", line, err)
+	}
+}
+
+// expect skips lines until line with want, e.g. notices the test does not check.
+func (c *ircClient) expect(want string) string {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(3 * time.Second)) //nolint:errcheck
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			c.t.Fatalf("expect %q: %s", want, err)
+		}
+		if strings.Contains(line, want) {
+			return strings.TrimSpace(line)
+		}
+	}
+}
+
+// register registers client with nick and returns client ID, nick is changed to.
+func (c *ircClient) register(nick string) string {
+	c.t.Helper()
+	c.send("NICK " + nick)
+	c.send("USER " + nick + " 0 * :" + nick)
+	clientID := strings.TrimPrefix(c.expect(":"+nick+" NICK "), ":"+nick+" NICK ")
+	c.expect(" 001 " + clientID + " ")
+
+	return clientID
+}
+
+func TestIRCHandler(t *testing.T) {
+	t.Parallel()
+	servers := startServers(t)
+
+	alice := dialIRC(t, servers.irc)
+	aliceID := alice.register("alice")
+	bob := dialIRC(t, servers.irc)
+	bobID := bob.register("bob")
+
+	alice.send("PING :token")
+	alice.expect(":go-ws-chat PONG go-ws-chat :token")
+
+	alice.send("PRIVMSG " + bobID + " :hi bob")
+	bob.expect(":" + aliceID + "!" + aliceID + "@go-ws-chat PRIVMSG " + bobID + " :hi bob")
+
+	alice.send("JOIN #room")
+	alice.expect(":" + aliceID + "!" + aliceID + "@go-ws-chat JOIN #room")
+	alice.expect(" 366 " + aliceID + " #room :End of /NAMES list")
+	bob.send("JOIN #room")
+	bob.expect(" 366 " + bobID + " #room ")
+	bob.send("PRIVMSG #room :hi room")
+	alice.expect(":" + bobID + "!" + bobID + "@go-ws-chat PRIVMSG #room :hi room")
+
+	bob.send("PRIVMSG nobody :hi")
+	bob.expect(" 401 " + bobID + " nobody :No such nick/channel")
+
+	// server pings client every PingIntervalSeconds
+	alice.expect("PING :go-ws-chat")
+	alice.send("PONG :go-ws-chat")
+
+	bob.send("QUIT")
+	bob.expect("ERROR :Closing link")
+}
+
+func TestIRCHandlerRegistration(t *testing.T) {
+	t.Parallel()
+	servers := startServers(t)
+
+	client := dialIRC(t, servers.irc)
+	client.send("PRIVMSG bob :hi")
+	client.expect(" 451 * :You have not registered")
+	client.send("NICK")
+	client.expect(" 431 * :No nickname given")
+	if clientID := client.register("bot-echo"); clientID == "bot-echo" {
+		t.Fatal("nick is reserved for bots")
+	}
+}
+
+// TestIRCHandlerNickDoesNotTakeClientID checks, that IRC client with nick of disconnected WebSocket client does not
+// get messages queued for it.
+func TestIRCHandlerNickDoesNotTakeClientID(t *testing.T) {
+	t.Parallel()
+	servers := startServers(t)
+
+	webSocket, settings := dialWebSocket(t, servers.webSocket)
+	webSocket.Close() //nolint:errcheck
+	deadline := time.Now().Add(3 * time.Second)
+	for online, _ := servers.pubSubHub.Online(context.Background()); slices.Contains(online, settings.ID); {
+		if time.Now().After(deadline) {
+			t.Fatal("WebSocket client is online after disconnect")
+		}
+		time.Sleep(10 * time.Millisecond)
+		online, _ = servers.pubSubHub.Online(context.Background())
+	}
+
+	sender := dialIRC(t, servers.irc)
+	sender.register("sender")
+	sender.send("PRIVMSG " + settings.ID + " :secret")
+	sender.send("PING :sent")
+	sender.expect("PONG go-ws-chat :sent")
+
+	thief := dialIRC(t, servers.irc)
+	if clientID := thief.register(settings.ID); clientID == settings.ID {
+		t.Fatal("nick is client ID of other client")
+	}
+	thief.conn.SetReadDeadline(time.Now().Add(300 * time.Millisecond)) //nolint:errcheck
+	for {
+		line, err := thief.reader.ReadString('\n')
+		if err != nil {
+			break
+		}
+		if strings.Contains(line, "secret") {
+			t.Fatalf("message of other client is received: %q", line)
+		}
+	}
+
+	// message is queued for WebSocket client, which gets it when it is back
+	webSocket, _ = dialWebSocket(t, servers.webSocket+"?"+chat.ResumeQueryParam+"="+settings.ResumeToken)
+	webSocket.SetReadDeadline(time.Now().Add(3 * time.Second)) //nolint:errcheck
+	_, data, err := webSocket.ReadMessage()
+	if err != nil || !strings.Contains(string(data), "secret") {
+		t.Fatalf("queued message = %s, error: %v", data, err)
+	}
+}
+
+func dialWebSocket(t *testing.T, url string) (*websocket.Conn, chat.SettingsMessage) {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() }) //nolint:errcheck
+
+	var settings chat.SettingsMessage
+	conn.SetReadDeadline(time.Now().Add(3 * time.Second)) //nolint:errcheck
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = json.Unmarshal(data, &settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conn, settings
+}
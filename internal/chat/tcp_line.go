@@ -0,0 +1,282 @@
+package chat
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	lineCommands = "commands: /to <client ID> <text>, /who, /quit"
+	// tcpSignInTimeout is a time TCP client has to sign in, then connection is closed
+	tcpSignInTimeout = 30 * time.Second
+)
+
+// lineMessageWrite is a part of any message to client, line handler shows.
+type lineMessageWrite struct {
+	Typ      messageType `json:"type"`
+	ClientID string      `json:"clientID"`
+	From     string      `json:"from"`
+	To       string      `json:"to"`
+	Room     string      `json:"room"`
+	Text     string      `json:"text"`
+	Code     errorCode   `json:"code"`
+	Clients  []string    `json:"clients"`
+}
+
+type lineHandler struct {
+	logger    Logger
+	config    ClientConfig
+	connector *connector
+}
+
+// lineConn is a TCP connection of line handler, lines are written from reading and writing goroutines.
+type lineConn struct {
+	conn net.Conn
+	// readTimeout is a time client may send nothing, zero - no limit
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+	// signInUntil limits reading of lines before client is signed in, zero after it
+	signInUntil time.Time
+	mu          sync.Mutex
+}
+
+// NewLineHandler serves raw TCP connections with simple line protocol for terminal users, e.g. nc or telnet: client
+// sends commands, messages to client are shown one per line. Client is authenticated like WebSocket one, with token
+// from command /token <token>. Signed in client is not pinged, its connection is closed when it sends nothing for
+// TCPIdleTimeoutSeconds.
+func NewLineHandler(logger Logger, connector *connector) *lineHandler { //nolint:revive
+	return &lineHandler{
+		logger:    logger,
+		config:    connector.config,
+		connector: connector,
+	}
+}
+
+func (h *lineHandler) ServeConn(ctx context.Context, conn net.Conn) {
+	lines := newLineConn(conn, h.config)
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, h.config.ReadLimitPerMessage), h.config.ReadLimitPerMessage)
+
+	client, ok := h.identify(ctx, lines, scanner)
+	if !ok {
+		return
+	}
+	lines.signInUntil = time.Time{}
+	// terminal users may only read, so signed in client is not pinged and has long idle timeout
+	lines.readTimeout = time.Duration(h.config.TCPIdleTimeoutSeconds) * time.Second
+	h.logInfo(ctx, "chat, lineHandler, ServeConn", "new connect, clientID: "+client.clientID)
+
+	chatConn := h.connector.connect(ctx, client, tcpRemoteIP(conn), SubprotocolV1JSON)
+	writeDone := make(chan struct{})
+	go func() {
+		defer close(writeDone)
+		h.write(ctx, lines, client.clientID, chatConn.writeCh)
+	}()
+	h.read(ctx, lines, scanner, chatConn)
+	<-writeDone
+}
+
+// identify authenticates client with empty token, if it is rejected asks for /token command.
+func (h *lineHandler) identify(ctx context.Context, lines *lineConn, scanner *bufio.Scanner) (identity, bool) {
+	client, err := h.connector.identify(lineRequest(ctx, lines.conn, ""))
+	if err == nil {
+		return client, true
+	}
+
+	lines.write("* sign in with: /token <token>, or /quit")
+	for lines.scan(scanner) {
+		command, argument, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		switch command {
+		case "/token":
+			client, err = h.connector.identify(lineRequest(ctx, lines.conn, argument))
+			if err == nil {
+				return client, true
+			}
+			h.logWarn(ctx, "chat, lineHandler, identify", err)
+			lines.write("! unauthorized")
+		case "/quit":
+			return identity{}, false
+		default:
+			lines.write("! sign in first")
+		}
+	}
+	if errors.Is(scanner.Err(), os.ErrDeadlineExceeded) {
+		lines.write("! timeout")
+	}
+
+	return identity{}, false
+}
+
+// read turns commands into messages of client until /quit or connection is closed.
+func (h *lineHandler) read(ctx context.Context, lines *lineConn, scanner *bufio.Scanner, chatConn *connection) {
+	defer chatConn.close()
+
+	lines.write("* " + lineCommands)
+	for lines.scan(scanner) {
+		command, argument, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		var message any
+		switch command {
+		case "":
+			continue
+		case "/quit":
+			lines.write("* bye")
+
+			return
+		case "/who":
+			var typedMessageRead TypedMessageRead
+			typedMessageRead.Typ = messageTypeOnline
+			message = typedMessageRead
+		case "/to":
+			var textMessageRead TextMessageRead
+			textMessageRead.Typ = messageTypeText
+			textMessageRead.To, textMessageRead.Text, _ = strings.Cut(strings.TrimSpace(argument), " ")
+			message = textMessageRead
+		default:
+			lines.write("! unknown command, " + lineCommands)
+
+			continue
+		}
+
+		err := chatConn.readMessage(ctx, message)
+		if errors.Is(err, errRateLimitExceeded) {
+			lines.write("! " + err.Error())
+		}
+		if err != nil {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		h.logDebug(ctx, "chat, lineHandler, read, scanner.Scan", err.Error())
+	}
+}
+
+// write shows messages to client until writeCh is closed, then closes connection.
+func (h *lineHandler) write(ctx context.Context, lines *lineConn, clientID string, writeCh chan frame) {
+	defer lines.conn.Close() //nolint:errcheck
+
+	for message := range writeCh {
+		if message.binary {
+			continue
+		}
+
+		var lineMessage lineMessageWrite
+		err := json.Unmarshal(message.data, &lineMessage)
+		if err != nil {
+			h.logError(ctx, "chat, lineHandler, write, json.Unmarshal", err)
+
+			continue
+		}
+
+		var line string
+		switch lineMessage.Typ { //nolint:exhaustive
+		case messageTypeSettings:
+			line = "* signed in as " + lineMessage.ClientID
+		case messageTypeText:
+			line = lineMessage.From + ": " + lineMessage.Text
+			if lineMessage.From == clientID {
+				line = "-> " + lineMessage.To + ": " + lineMessage.Text
+			}
+		case messageTypeRoomText:
+			line = "[" + lineMessage.Room + "] " + lineMessage.From + ": " + lineMessage.Text
+		case messageTypeError:
+			line = "! " + string(lineMessage.Code) + ": " + lineMessage.Text
+		case messageTypeOnline:
+			line = "* online: " + strings.Join(lineMessage.Clients, ", ")
+		default:
+			continue
+		}
+
+		if !lines.write(line) {
+			return
+		}
+	}
+}
+
+func newLineConn(conn net.Conn, config ClientConfig) *lineConn {
+	return &lineConn{
+		conn:         conn,
+		readTimeout:  time.Duration(config.ReadTimeoutSeconds) * time.Second,
+		writeTimeout: time.Duration(config.WriteTimeoutSeconds) * time.Second,
+		signInUntil:  time.Now().Add(tcpSignInTimeout),
+	}
+}
+
+// scan reads the next line, connection is closed when client sends nothing for readTimeout or is not signed in until
+// signInUntil.
+func (c *lineConn) scan(scanner *bufio.Scanner) bool {
+	var deadline time.Time
+	if c.readTimeout > 0 {
+		deadline = time.Now().Add(c.readTimeout)
+	}
+	if !c.signInUntil.IsZero() && (deadline.IsZero() || c.signInUntil.Before(deadline)) {
+		deadline = c.signInUntil
+	}
+	c.conn.SetReadDeadline(deadline) //nolint:errcheck
+
+	return scanner.Scan()
+}
+
+// write sends line without control characters, so text of other clients can't control terminal.
+func (c *lineConn) write(line string) bool {
+	line = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\t' {
+			return ' '
+		}
+
+		return r
+	}, line)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout)) //nolint:errcheck
+	_, err := c.conn.Write([]byte(line + "\r\n"))
+
+	return err == nil
+}
+
+func tcpRemoteIP(conn net.Conn) string {
+	remoteIP, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		return conn.RemoteAddr().String()
+	}
+
+	return remoteIP
+}
+
+// lineRequest makes request for Authenticator with token, as if it is sent in TokenQueryParam.
+func lineRequest(ctx context.Context, conn net.Conn, token string) *http.Request {
+	request := &http.Request{
+		Method:     http.MethodGet,
+		URL:        &url.URL{Path: "/", RawQuery: url.Values{TokenQueryParam: {token}}.Encode()},
+		Header:     make(http.Header),
+		RemoteAddr: conn.RemoteAddr().String(),
+	}
+
+	return request.WithContext(ctx)
+}
+
+func (h *lineHandler) logError(ctx context.Context, point string, err error) {
+	h.logger.ErrorfContext(ctx, "%s, error: %s", point, err)
+}
+
+func (h *lineHandler) logWarn(ctx context.Context, point string, err error) {
+	h.logger.WarnfContext(ctx, "%s, error: %s", point, err)
+}
+
+func (h *lineHandler) logInfo(ctx context.Context, point, msg string) {
+	h.logger.InfofContext(ctx, "%s, msg: %s", point, msg)
+}
+
+func (h *lineHandler) logDebug(ctx context.Context, point, msg string) {
+	h.logger.DebugfContext(ctx, "%s, msg: %s", point, msg)
+}
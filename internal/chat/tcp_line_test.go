@@ -0,0 +1,37 @@
+package chat_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dark705/go-ws-chat/internal/auth"
+	"github.com/dark705/go-ws-chat/internal/chat"
+	"github.com/dark705/go-ws-chat/internal/history"
+)
+
+func TestLineHandlerIdleClient(t *testing.T) {
+	t.Parallel()
+	connector := chat.NewConnector(nopLogger{}, auth.NewAnonymous(), chat.ClientConfig{
+		WriteTimeoutSeconds:   1,
+		ReadTimeoutSeconds:    1,
+		ReadLimitPerMessage:   2048,
+		PingIntervalSeconds:   1,
+		MaxTextLength:         1000,
+		TCPIdleTimeoutSeconds: 5,
+		Backpressure:          chat.BackpressureConfig{Policy: chat.BackpressureDisconnect},
+	}, newPubSubHub(), history.NewInmemory(nopLogger{}, 10), chat.NewRooms(), chat.NewResumeSessions(60), nopMetrics{},
+		nopNotifier{})
+	address := serveTCP(t, chat.NewLineHandler(nopLogger{}, connector))
+
+	// ircClient reads and writes lines of line protocol too
+	reader := dialIRC(t, address)
+	readerID := strings.TrimPrefix(reader.expect("* signed in as "), "* signed in as ")
+
+	// signed in client, which only reads, is not disconnected after ReadTimeoutSeconds
+	time.Sleep(1500 * time.Millisecond)
+	writer := dialIRC(t, address)
+	writer.expect("* signed in as ")
+	writer.send("/to " + readerID + " hi")
+	reader.expect(": hi")
+}
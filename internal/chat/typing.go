@@ -0,0 +1,90 @@
+package chat
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// typingExpire is time after typing started event, server sends typing stopped event on behalf of client.
+const typingExpire = 5 * time.Second
+
+type TypingMessageRead struct {
+	Message
+	ID     string `json:"id"`
+	To     string `json:"to"`
+	Typing bool   `json:"typing"`
+}
+
+type TypingMessageWrite struct {
+	Message
+	From   string    `json:"from"`
+	Typing bool      `json:"typing"`
+	Time   time.Time `json:"time"`
+}
+
+// typingTimers keeps expire timers of typing started events per peer.
+type typingTimers struct {
+	mu     sync.Mutex
+	timers map[string]*time.Timer
+}
+
+func (m TypingMessageRead) validate(_ int) error {
+	return required("to", m.To)
+}
+
+func (h *oneToOneHandler) readTyping(ctx context.Context, typingMessageRead TypingMessageRead) {
+	peerID := typingMessageRead.To
+	h.typing.mu.Lock()
+	if timer, found := h.typing.timers[peerID]; found {
+		timer.Stop()
+		delete(h.typing.timers, peerID)
+	}
+	if typingMessageRead.Typing {
+		var timer *time.Timer
+		timer = time.AfterFunc(typingExpire, func() {
+			h.typing.mu.Lock()
+			// timer may be stopped too late and replaced by timer of the next typing started event
+			expired := h.typing.timers[peerID] == timer
+			if expired {
+				delete(h.typing.timers, peerID)
+			}
+			h.typing.mu.Unlock()
+			if expired {
+				h.pubTyping(ctx, peerID, false)
+			}
+		})
+		h.typing.timers[peerID] = timer
+	}
+	h.typing.mu.Unlock()
+
+	h.pubTyping(ctx, peerID, typingMessageRead.Typing)
+}
+
+// stopTyping sends typing stopped events to all peers client is typing to, on disconnect.
+func (h *oneToOneHandler) stopTyping(ctx context.Context) {
+	h.typing.mu.Lock()
+	peerIDs := make([]string, 0, len(h.typing.timers))
+	for peerID, timer := range h.typing.timers {
+		timer.Stop()
+		peerIDs = append(peerIDs, peerID)
+	}
+	clear(h.typing.timers)
+	h.typing.mu.Unlock()
+
+	for _, peerID := range peerIDs {
+		h.pubTyping(ctx, peerID, false)
+	}
+}
+
+// pubTyping publishes ephemeral typing event, it is neither queued for offline peer nor saved to history.
+func (h *oneToOneHandler) pubTyping(ctx context.Context, peerID string, typing bool) {
+	envelope := h.newEnvelope(messageTypeTyping, peerID, "", "")
+	envelope.Typing = typing
+	envelope.Ephemeral = true
+
+	err := h.pubSubHub.Pub(ctx, peerID, envelope)
+	if err != nil {
+		h.logDebug(ctx, "chat, oneToOneHandler, pubTyping, pubSubHub.Pub", err.Error())
+	}
+}
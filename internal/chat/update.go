@@ -0,0 +1,224 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"time"
+	"unicode/utf8"
+)
+
+const maxReactionLength = 16
+
+var (
+	errNotMessageSender = errors.New("only sender can change message")
+	errMessageDeleted   = errors.New("message is deleted")
+	errReactionTooLong  = errors.New("reaction is too long")
+)
+
+// EditMessageRead replaces text of own message MessageID of conversation with peer (With) or of room (Room).
+type EditMessageRead struct {
+	Message
+	ID        string `json:"id"`
+	MessageID string `json:"messageID"`
+	With      string `json:"with"`
+	Room      string `json:"room"`
+	Text      string `json:"text"`
+}
+
+// DeleteMessageRead deletes own message MessageID, the message is kept in history with deleted flag and no text.
+type DeleteMessageRead struct {
+	Message
+	ID        string `json:"id"`
+	MessageID string `json:"messageID"`
+	With      string `json:"with"`
+	Room      string `json:"room"`
+}
+
+// ReactMessageRead adds Reaction of client to message MessageID, or removes it if it is already added.
+type ReactMessageRead struct {
+	Message
+	ID        string `json:"id"`
+	MessageID string `json:"messageID"`
+	With      string `json:"with"`
+	Room      string `json:"room"`
+	Reaction  string `json:"reaction"`
+}
+
+// UpdateMessageWrite is sent to participants of conversation on edit, delete and reaction of message MessageID,
+// it contains the current state of the message.
+type UpdateMessageWrite struct {
+	Message
+	From      string              `json:"from"`
+	To        string              `json:"to,omitempty"`
+	Room      string              `json:"room,omitempty"`
+	MessageID string              `json:"messageID"`
+	Text      string              `json:"text,omitempty"`
+	Edited    bool                `json:"edited,omitempty"`
+	Deleted   bool                `json:"deleted,omitempty"`
+	Reactions map[string][]string `json:"reactions,omitempty"`
+	Time      time.Time           `json:"time"`
+}
+
+func (m EditMessageRead) validate(maxTextLength int) error {
+	err := validConversationMessage(m.MessageID, m.With, m.Room)
+	if err != nil {
+		return err
+	}
+
+	return validText(m.Text, maxTextLength)
+}
+
+func (m DeleteMessageRead) validate(_ int) error {
+	return validConversationMessage(m.MessageID, m.With, m.Room)
+}
+
+func (m ReactMessageRead) validate(_ int) error {
+	err := errors.Join(validConversationMessage(m.MessageID, m.With, m.Room), required("reaction", m.Reaction))
+	if err != nil {
+		return err
+	}
+	if utf8.RuneCountInString(m.Reaction) > maxReactionLength {
+		return errReactionTooLong
+	}
+
+	return nil
+}
+
+func validConversationMessage(messageID, with, room string) error {
+	err := required("messageID", messageID)
+	if err != nil {
+		return err
+	}
+	if room == "" {
+		return required("with", with)
+	}
+
+	return nil
+}
+
+func (h *oneToOneHandler) readEdit(ctx context.Context, editMessageRead EditMessageRead) {
+	h.updateMessage(ctx, editMessageRead.Typ, editMessageRead.ID, editMessageRead.MessageID,
+		editMessageRead.With, editMessageRead.Room, func(envelope *Envelope) error {
+			if envelope.From != h.clientID {
+				return errNotMessageSender
+			}
+			if envelope.Deleted {
+				return errMessageDeleted
+			}
+			envelope.Text = editMessageRead.Text
+			envelope.Edited = true
+
+			return nil
+		})
+}
+
+func (h *oneToOneHandler) readDelete(ctx context.Context, deleteMessageRead DeleteMessageRead) {
+	h.updateMessage(ctx, deleteMessageRead.Typ, deleteMessageRead.ID, deleteMessageRead.MessageID,
+		deleteMessageRead.With, deleteMessageRead.Room, func(envelope *Envelope) error {
+			if envelope.From != h.clientID {
+				return errNotMessageSender
+			}
+			envelope.Text = ""
+			envelope.Deleted = true
+			envelope.Reactions = nil
+
+			return nil
+		})
+}
+
+func (h *oneToOneHandler) readReact(ctx context.Context, reactMessageRead ReactMessageRead) {
+	h.updateMessage(ctx, reactMessageRead.Typ, reactMessageRead.ID, reactMessageRead.MessageID,
+		reactMessageRead.With, reactMessageRead.Room, func(envelope *Envelope) error {
+			if envelope.Deleted {
+				return errMessageDeleted
+			}
+			// reactions may be shared with envelopes already published, so they are copied on change
+			reactions := maps.Clone(envelope.Reactions)
+			if reactions == nil {
+				reactions = make(map[string][]string)
+			}
+			clientIDs := slices.Clone(reactions[reactMessageRead.Reaction])
+			if i := slices.Index(clientIDs, h.clientID); i >= 0 {
+				clientIDs = slices.Delete(clientIDs, i, i+1)
+			} else {
+				clientIDs = append(clientIDs, h.clientID)
+			}
+			reactions[reactMessageRead.Reaction] = clientIDs
+			if len(clientIDs) == 0 {
+				delete(reactions, reactMessageRead.Reaction)
+			}
+			envelope.Reactions = reactions
+
+			return nil
+		})
+}
+
+// updateMessage changes message in history and sends its new state to participants of conversation.
+func (h *oneToOneHandler) updateMessage(ctx context.Context, typ messageType, ref, messageID, with, room string,
+	update func(envelope *Envelope) error,
+) {
+	conversation := peerConversation(h.clientID, with)
+	if room != "" {
+		if !h.rooms.isMember(room, h.clientID) {
+			h.replyError(ctx, errorCodeNotRoomMember, ref, "not a member of room: "+room)
+
+			return
+		}
+		conversation = roomConversation(room)
+	}
+
+	updated, err := h.history.Update(ctx, conversation, messageID, update)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrMessageNotFound):
+			h.replyError(ctx, errorCodeMessageNotFound, ref, "message not found: "+messageID)
+		case errors.Is(err, errNotMessageSender), errors.Is(err, errMessageDeleted):
+			h.replyError(ctx, errorCodeForbidden, ref, err.Error())
+		default:
+			h.logError(ctx, "chat, oneToOneHandler, updateMessage, history.Update", err)
+			h.replyError(ctx, errorCodeInternal, ref, "fail update message")
+		}
+
+		return
+	}
+
+	envelope := h.newEnvelope(typ, with, room, updated.Text)
+	envelope.Ref = messageID
+	envelope.Edited = updated.Edited
+	envelope.Deleted = updated.Deleted
+	envelope.Reactions = updated.Reactions
+	if room != "" {
+		envelope.To = ""
+		h.pubRoom(ctx, envelope)
+
+		return
+	}
+
+	err = h.pubSubHub.Pub(ctx, with, envelope)
+	if err != nil {
+		h.logError(ctx, "chat, oneToOneHandler, updateMessage, pubSubHub.Pub", err)
+	}
+	if with != h.clientID {
+		h.pubEcho(ctx, envelope)
+	}
+}
+
+func newUpdateMessageWrite(envelope Envelope) UpdateMessageWrite {
+	var updateMessageWrite UpdateMessageWrite
+	updateMessageWrite.Typ = envelope.Typ
+	updateMessageWrite.From = envelope.From
+	if envelope.Room == "" {
+		updateMessageWrite.To = envelope.To
+	}
+	updateMessageWrite.Room = envelope.Room
+	updateMessageWrite.MessageID = envelope.Ref
+	updateMessageWrite.Text = envelope.Text
+	updateMessageWrite.Edited = envelope.Edited
+	updateMessageWrite.Deleted = envelope.Deleted
+	updateMessageWrite.Reactions = envelope.Reactions
+	updateMessageWrite.Time = envelope.Time
+
+	return updateMessageWrite
+}
@@ -6,6 +6,20 @@ import (
 	"github.com/caarlos0/env/v11"
 )
 
+const (
+	HistoryStoreInmemory = "inmemory"
+	HistoryStoreBolt     = "bolt"
+
+	AuthModeAnonymous = "anonymous"
+	AuthModeHMAC      = "hmac"
+	AuthModeJWT       = "jwt"
+
+	BotEcho = "echo"
+	BotHelp = "help"
+	BotTime = "time"
+	BotNone = "none"
+)
+
 type EnvConfig struct {
 	Version  string `env:"VERSION" envDefault:"version_not_set"`
 	LogLevel string `env:"LOG_LEVEL" envDefault:"info"`
@@ -14,13 +28,58 @@ type EnvConfig struct {
 	HTTPRequestHeaderMaxSize                 int    `env:"HTTP_REQUEST_HEADER_MAX_SIZE" envDefault:"10000"`
 	HTTPRequestReadHeaderTimeoutMilliseconds int    `env:"HTTP_REQUEST_READ_HEADER_TIMEOUT_MILLISECONDS" envDefault:"2000"`
 
+	TCPLinePort           string `env:"TCP_LINE_PORT"`
+	IRCPort               string `env:"IRC_PORT"`
+	TCPMaxConnections     int    `env:"TCP_MAX_CONNECTIONS" envDefault:"1000"`
+	TCPIdleTimeoutSeconds int    `env:"TCP_IDLE_TIMEOUT_SECONDS" envDefault:"3600"`
+
+	GRPCPort string `env:"GRPC_PORT"`
+
+	AuthMode   string `env:"AUTH_MODE" envDefault:"anonymous"`
+	AuthSecret string `env:"AUTH_SECRET"`
+
 	WebSocketUpgraderReadBufferSize     int  `env:"WEB_SOCKET_UPGRADER_READ_BUFFER_SIZE" envDefault:"2048"`
 	WebSocketUpgraderWriteBufferSize    int  `env:"WEB_SOCKET_UPGRADER_WRITE_BUFFER_SIZE" envDefault:"2048"`
 	WebSocketUpgraderCheckOrigin        bool `env:"WEB_SOCKET_UPGRADER_CHECK_ORIGIN" envDefault:"true"`
+	WebSocketUpgraderCompression        bool `env:"WEB_SOCKET_UPGRADER_COMPRESSION" envDefault:"false"`
 	WebSocketHandlerWriteTimeoutSeconds int  `env:"WEB_SOCKET_HANDLER_WRITE_TIMEOUT_SECONDS" envDefault:"20"`
 	WebSocketHandlerReadTimeoutSeconds  int  `env:"WEB_SOCKET_HANDLER_READ_TIMEOUT_SECONDS" envDefault:"20"`
 	WebSocketHandlerReadLimitPerMessage int  `env:"WEB_SOCKET_HANDLER_READ_LIMIT_PER_MESSAGE" envDefault:"2048"`
 	WebSocketHandlerPingIntervalSeconds int  `env:"WEB_SOCKET_HANDLER_PING_INTERVAL_SECONDS" envDefault:"5"`
+	WebSocketHandlerMaxTextLength       int  `env:"WEB_SOCKET_HANDLER_MAX_TEXT_LENGTH" envDefault:"1000"`
+	WebSocketHandlerResumeGraceSeconds  int  `env:"WEB_SOCKET_HANDLER_RESUME_GRACE_SECONDS" envDefault:"60"`
+	WebSocketHandlerCompressionLevel    int  `env:"WEB_SOCKET_HANDLER_COMPRESSION_LEVEL" envDefault:"1"`
+	WebSocketHandlerCompressionMinSize  int  `env:"WEB_SOCKET_HANDLER_COMPRESSION_MIN_SIZE" envDefault:"256"`
+
+	WebSocketHandlerBackpressurePolicy                   string `env:"WEB_SOCKET_HANDLER_BACKPRESSURE_POLICY" envDefault:"disconnect"`
+	WebSocketHandlerBackpressureBlockTimeoutMilliseconds int    `env:"WEB_SOCKET_HANDLER_BACKPRESSURE_BLOCK_TIMEOUT_MILLISECONDS" envDefault:"1000"`
+
+	LongPollTimeoutSeconds     int `env:"LONG_POLL_TIMEOUT_SECONDS" envDefault:"25"`
+	LongPollSessionIdleSeconds int `env:"LONG_POLL_SESSION_IDLE_SECONDS" envDefault:"60"`
+
+	RateLimitAction                      string `env:"RATE_LIMIT_ACTION" envDefault:"drop"`
+	RateLimitConnectionMessagesPerSecond int    `env:"RATE_LIMIT_CONNECTION_MESSAGES_PER_SECOND" envDefault:"20"`
+	RateLimitConnectionBytesPerSecond    int    `env:"RATE_LIMIT_CONNECTION_BYTES_PER_SECOND" envDefault:"32768"`
+	RateLimitIPMessagesPerSecond         int    `env:"RATE_LIMIT_IP_MESSAGES_PER_SECOND" envDefault:"100"`
+	RateLimitIPBytesPerSecond            int    `env:"RATE_LIMIT_IP_BYTES_PER_SECOND" envDefault:"131072"`
+
+	Bots []string `env:"BOTS" envDefault:"echo,help,time"`
+
+	WebhookURLs                     []string `env:"WEBHOOK_URLS"`
+	WebhookSecret                   string   `env:"WEBHOOK_SECRET"`
+	WebhookQueueSize                int      `env:"WEBHOOK_QUEUE_SIZE" envDefault:"1000"`
+	WebhookMaxRetries               int      `env:"WEBHOOK_MAX_RETRIES" envDefault:"5"`
+	WebhookRetryBackoffMilliseconds int      `env:"WEBHOOK_RETRY_BACKOFF_MILLISECONDS" envDefault:"500"`
+	WebhookTimeoutMilliseconds      int      `env:"WEBHOOK_TIMEOUT_MILLISECONDS" envDefault:"5000"`
+
+	PubSubOfflineQueueSize          int `env:"PUB_SUB_OFFLINE_QUEUE_SIZE" envDefault:"100"`
+	PubSubOfflineQueueTTLSeconds    int `env:"PUB_SUB_OFFLINE_QUEUE_TTL_SECONDS" envDefault:"300"`
+	PubSubOfflineQueueMaxRecipients int `env:"PUB_SUB_OFFLINE_QUEUE_MAX_RECIPIENTS" envDefault:"10000"`
+	PubSubOfflineQueueMaxBytes      int `env:"PUB_SUB_OFFLINE_QUEUE_MAX_BYTES" envDefault:"16777216"`
+
+	HistoryStore                      string `env:"HISTORY_STORE" envDefault:"inmemory"`
+	HistoryInmemoryMaxPerConversation int    `env:"HISTORY_INMEMORY_MAX_PER_CONVERSATION" envDefault:"1000"`
+	HistoryBoltPath                   string `env:"HISTORY_BOLT_PATH" envDefault:"/data/history.db"`
 
 	PrometheusPort string `env:"PROMETHEUS_PORT" envDefault:"9000"`
 
@@ -0,0 +1,92 @@
+package grpcserver
+
+import (
+	"context"
+	"log"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+type Logger interface {
+	Debugf(format string, args ...any)
+	DebugfContext(ctx context.Context, format string, args ...any)
+
+	Infof(format string, args ...any)
+	InfofContext(ctx context.Context, format string, args ...any)
+
+	Warnf(format string, args ...any)
+	WarnfContext(ctx context.Context, format string, args ...any)
+
+	Errorf(format string, args ...any)
+	ErrorfContext(ctx context.Context, format string, args ...any)
+
+	Fatalf(format string, args ...any)
+	FatalfContext(ctx context.Context, format string, args ...any)
+}
+
+const (
+	shutdownMaxTimeout = 5 * time.Second
+)
+
+type Server struct {
+	grpcServer *grpc.Server
+	logger     Logger
+	config     Config
+}
+
+type Config struct {
+	Name           string
+	GRPCListenIP   string
+	GRPCListenPort string
+}
+
+// NewServer serves service with serviceDesc, e.g. generated ChatService_ServiceDesc. Server reflection is enabled, so
+// tools like grpcurl work without proto files.
+func NewServer(config Config, logger Logger, serviceDesc *grpc.ServiceDesc, service any) *Server {
+	grpcServer := grpc.NewServer()
+	grpcServer.RegisterService(serviceDesc, service)
+	reflection.Register(grpcServer)
+
+	return &Server{
+		logger:     logger,
+		config:     config,
+		grpcServer: grpcServer,
+	}
+}
+
+func (s *Server) Run() {
+	address := s.config.GRPCListenIP + ":" + s.config.GRPCListenPort
+	s.logger.Infof("%s GRPCServer, start on: %s", s.config.Name, address)
+	listener, err := net.Listen("tcp", address)
+	failOnError(err, s.config.Name+" GRPCServer, fail open port")
+	go func() {
+		err = s.grpcServer.Serve(listener)
+		failOnError(err, s.config.Name+" GRPCServer, fail start")
+	}()
+}
+
+// Stop waits for running calls to finish, streams which are still open after shutdownMaxTimeout are closed.
+func (s *Server) Stop() {
+	s.logger.Infof(s.config.Name + " GRPCServer, stop...")
+	done := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		s.logger.Infof(s.config.Name + " GRPCServer, success stop")
+	case <-time.After(shutdownMaxTimeout):
+		s.grpcServer.Stop()
+		s.logger.Errorf(s.config.Name + " GRPCServer, fail graceful stop, streams are closed")
+	}
+}
+
+func failOnError(err error, message string) {
+	if err != nil {
+		log.Fatalf("%s: %s", message, err)
+	}
+}
@@ -0,0 +1,220 @@
+package history
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/dark705/go-ws-chat/internal/chat"
+	bbolt "go.etcd.io/bbolt"
+)
+
+const (
+	boltOpenTimeout = 5 * time.Second
+	boltFileMode    = 0o600
+)
+
+var (
+	boltBucketConversations = []byte("conversations")
+	boltBucketMessages      = []byte("messages")
+)
+
+// bolt stores envelopes in bbolt file. Each conversation is a bucket with envelopes keyed by sequence,
+// messages bucket maps message ID to the sequence for pagination.
+type bolt struct {
+	logger Logger
+	db     *bbolt.DB
+}
+
+func NewBolt(logger Logger, path string) *bolt {
+	db, err := bbolt.Open(path, boltFileMode, &bbolt.Options{Timeout: boltOpenTimeout})
+	failOnError(err, "history, NewBolt, fail open bolt file: "+path)
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, bucket := range [][]byte{boltBucketConversations, boltBucketMessages} {
+			_, err := tx.CreateBucketIfNotExists(bucket)
+			if err != nil {
+				return fmt.Errorf("tx.CreateBucketIfNotExists: %w", err)
+			}
+		}
+
+		return nil
+	})
+	failOnError(err, "history, NewBolt, fail create buckets")
+
+	return &bolt{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (s *bolt) Save(ctx context.Context, conversation string, envelope chat.Envelope) error {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("history, bolt, Save, json.Marshal: %w", err)
+	}
+
+	err = s.db.Update(func(tx *bbolt.Tx) error {
+		conversationBucket, err := tx.Bucket(boltBucketConversations).CreateBucketIfNotExists([]byte(conversation))
+		if err != nil {
+			return fmt.Errorf("CreateBucketIfNotExists: %w", err)
+		}
+
+		sequence, err := conversationBucket.NextSequence()
+		if err != nil {
+			return fmt.Errorf("NextSequence: %w", err)
+		}
+		key := sequenceKey(sequence)
+
+		err = conversationBucket.Put(key, data)
+		if err != nil {
+			return fmt.Errorf("conversation Put: %w", err)
+		}
+
+		err = tx.Bucket(boltBucketMessages).Put([]byte(envelope.ID), key)
+		if err != nil {
+			return fmt.Errorf("messages Put: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("history, bolt, Save, db.Update: %w", err)
+	}
+	s.logger.DebugfContext(ctx, "history, bolt, Save, conversation: %s, message ID: %s", conversation, envelope.ID)
+
+	return nil
+}
+
+func (s *bolt) List(_ context.Context, conversation, beforeID string, limit int) ([]chat.Envelope, error) {
+	var envelopes []chat.Envelope
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		conversationBucket := tx.Bucket(boltBucketConversations).Bucket([]byte(conversation))
+		if conversationBucket == nil && beforeID != "" {
+			return fmt.Errorf("message ID: %s, %w", beforeID, chat.ErrMessageNotFound)
+		}
+		if conversationBucket == nil {
+			return nil
+		}
+
+		cursor := conversationBucket.Cursor()
+		key, value := cursor.Last()
+		if beforeID != "" {
+			beforeKey := tx.Bucket(boltBucketMessages).Get([]byte(beforeID))
+			if beforeKey == nil || !hasMessage(conversationBucket, beforeKey, beforeID) {
+				return fmt.Errorf("message ID: %s, %w", beforeID, chat.ErrMessageNotFound)
+			}
+			cursor.Seek(beforeKey)
+			key, value = cursor.Prev()
+		}
+
+		for ; key != nil && len(envelopes) < limit; key, value = cursor.Prev() {
+			var envelope chat.Envelope
+			err := json.Unmarshal(value, &envelope)
+			if err != nil {
+				return fmt.Errorf("json.Unmarshal: %w", err)
+			}
+			envelopes = append(envelopes, envelope)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("history, bolt, List, db.View: %w", err)
+	}
+
+	slices.Reverse(envelopes)
+
+	return envelopes, nil
+}
+
+func (s *bolt) Update(ctx context.Context, conversation, id string,
+	update func(envelope *chat.Envelope) error,
+) (chat.Envelope, error) {
+	var envelope chat.Envelope
+
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		conversationBucket := tx.Bucket(boltBucketConversations).Bucket([]byte(conversation))
+		key := tx.Bucket(boltBucketMessages).Get([]byte(id))
+		if conversationBucket == nil || key == nil {
+			return fmt.Errorf("message ID: %s, %w", id, chat.ErrMessageNotFound)
+		}
+		value := conversationBucket.Get(key)
+		if value == nil {
+			return fmt.Errorf("message ID: %s, %w", id, chat.ErrMessageNotFound)
+		}
+
+		err := json.Unmarshal(value, &envelope)
+		if err != nil {
+			return fmt.Errorf("json.Unmarshal: %w", err)
+		}
+		if envelope.ID != id { // key of message of other conversation
+			return fmt.Errorf("message ID: %s, %w", id, chat.ErrMessageNotFound)
+		}
+
+		err = update(&envelope)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(envelope)
+		if err != nil {
+			return fmt.Errorf("json.Marshal: %w", err)
+		}
+
+		err = conversationBucket.Put(key, data)
+		if err != nil {
+			return fmt.Errorf("conversation Put: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return chat.Envelope{}, fmt.Errorf("history, bolt, Update, db.Update: %w", err)
+	}
+	s.logger.DebugfContext(ctx, "history, bolt, Update, conversation: %s, message ID: %s", conversation, id)
+
+	return envelope, nil
+}
+
+func (s *bolt) Close() error {
+	err := s.db.Close()
+	if err != nil {
+		return fmt.Errorf("history, bolt, Close, db.Close: %w", err)
+	}
+
+	return nil
+}
+
+// hasMessage checks that key of message ID is a key of conversation, keys are sequences of each conversation.
+func hasMessage(conversationBucket *bbolt.Bucket, key []byte, id string) bool {
+	value := conversationBucket.Get(key)
+	if value == nil {
+		return false
+	}
+
+	var envelope struct {
+		ID string `json:"id"`
+	}
+	err := json.Unmarshal(value, &envelope)
+
+	return err == nil && envelope.ID == id
+}
+
+func sequenceKey(sequence uint64) []byte {
+	key := make([]byte, 8) //nolint:mnd
+	binary.BigEndian.PutUint64(key, sequence)
+
+	return key
+}
+
+func failOnError(err error, message string) {
+	if err != nil {
+		log.Fatalf("%s: %s", message, err)
+	}
+}
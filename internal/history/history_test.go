@@ -0,0 +1,75 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/dark705/go-ws-chat/internal/chat"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) DebugfContext(context.Context, string, ...any) {}
+func (nopLogger) InfofContext(context.Context, string, ...any)  {}
+
+// stores are both implementations of chat.HistoryStore, they must behave the same.
+func stores(t *testing.T) map[string]chat.HistoryStore {
+	t.Helper()
+	boltStore := NewBolt(nopLogger{}, filepath.Join(t.TempDir(), "history.db"))
+	t.Cleanup(func() { _ = boltStore.Close() })
+
+	return map[string]chat.HistoryStore{
+		"inmemory": NewInmemory(nopLogger{}, 100),
+		"bolt":     boltStore,
+	}
+}
+
+func TestList(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		conversation string
+		before       string
+		limit        int
+		want         []string
+		wantErr      error
+	}{
+		{name: "latest", conversation: "a", limit: 2, want: []string{"a2", "a3"}},
+		{name: "before", conversation: "a", before: "a3", limit: 10, want: []string{"a1", "a2"}},
+		{name: "before the first", conversation: "a", before: "a1", limit: 10, want: nil},
+		{name: "empty conversation", conversation: "c", limit: 10, want: nil},
+		{name: "unknown before", conversation: "a", before: "x", limit: 10, wantErr: chat.ErrMessageNotFound},
+		{name: "before of other conversation", conversation: "a", before: "b1", limit: 10, wantErr: chat.ErrMessageNotFound},
+		{name: "before in empty conversation", conversation: "c", before: "a1", limit: 10, wantErr: chat.ErrMessageNotFound},
+	}
+
+	for storeName, store := range stores(t) {
+		ctx := context.Background()
+		for _, envelope := range []chat.Envelope{{ID: "a1"}, {ID: "b1"}, {ID: "a2"}, {ID: "a3"}} {
+			if err := store.Save(ctx, envelope.ID[:1], envelope); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		for _, test := range tests {
+			t.Run(storeName+"/"+test.name, func(t *testing.T) {
+				envelopes, err := store.List(ctx, test.conversation, test.before, test.limit)
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("List() error = %v, want %v", err, test.wantErr)
+				}
+
+				var ids []string
+				for _, envelope := range envelopes {
+					ids = append(ids, envelope.ID)
+				}
+				if !slices.Equal(ids, test.want) {
+					t.Fatalf("List() = %v, want %v", ids, test.want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/dark705/go-ws-chat/internal/chat"
+)
+
+type inmemory struct {
+	logger                 Logger
+	maxPerConversation     int
+	mu                     sync.RWMutex
+	conversationsEnvelopes map[string][]chat.Envelope
+}
+
+func NewInmemory(logger Logger, maxPerConversation int) *inmemory {
+	return &inmemory{
+		logger:                 logger,
+		maxPerConversation:     maxPerConversation,
+		conversationsEnvelopes: make(map[string][]chat.Envelope),
+	}
+}
+
+func (s *inmemory) Save(ctx context.Context, conversation string, envelope chat.Envelope) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	envelopes := append(s.conversationsEnvelopes[conversation], envelope)
+	if len(envelopes) > s.maxPerConversation {
+		envelopes = envelopes[len(envelopes)-s.maxPerConversation:]
+	}
+	s.conversationsEnvelopes[conversation] = envelopes
+	s.logger.DebugfContext(ctx, "history, inmemory, Save, conversation: %s, message ID: %s", conversation, envelope.ID)
+
+	return nil
+}
+
+func (s *inmemory) Update(ctx context.Context, conversation, id string,
+	update func(envelope *chat.Envelope) error,
+) (chat.Envelope, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	envelopes := s.conversationsEnvelopes[conversation]
+	for i := len(envelopes) - 1; i >= 0; i-- {
+		if envelopes[i].ID != id {
+			continue
+		}
+
+		envelope := envelopes[i]
+		err := update(&envelope)
+		if err != nil {
+			return chat.Envelope{}, err
+		}
+		envelopes[i] = envelope
+		s.logger.DebugfContext(ctx, "history, inmemory, Update, conversation: %s, message ID: %s", conversation, id)
+
+		return envelope, nil
+	}
+
+	return chat.Envelope{}, fmt.Errorf("history, inmemory, Update, message ID: %s, %w", id, chat.ErrMessageNotFound)
+}
+
+func (s *inmemory) List(_ context.Context, conversation, beforeID string, limit int) ([]chat.Envelope, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	envelopes := s.conversationsEnvelopes[conversation]
+	end := len(envelopes)
+	if beforeID != "" {
+		end = -1
+		for i := len(envelopes) - 1; i >= 0; i-- {
+			if envelopes[i].ID == beforeID {
+				end = i
+
+				break
+			}
+		}
+		if end < 0 {
+			return nil, fmt.Errorf("history, inmemory, List, message ID: %s, %w", beforeID, chat.ErrMessageNotFound)
+		}
+	}
+	start := max(end-limit, 0)
+
+	result := make([]chat.Envelope, end-start)
+	copy(result, envelopes[start:end])
+
+	return result, nil
+}
@@ -0,0 +1,8 @@
+package history
+
+import "context"
+
+type Logger interface {
+	DebugfContext(ctx context.Context, format string, args ...any)
+	InfofContext(ctx context.Context, format string, args ...any)
+}
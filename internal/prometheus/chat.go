@@ -0,0 +1,66 @@
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	labelDirection = "direction"
+	labelScope     = "scope"
+	labelAction    = "action"
+	labelPolicy    = "policy"
+	labelResult    = "result"
+)
+
+type chatMetrics struct {
+	webSocketWireBytes    *prometheus.CounterVec
+	webSocketPayloadBytes *prometheus.CounterVec
+	rateLimited           *prometheus.CounterVec
+	backpressure          *prometheus.CounterVec
+}
+
+func NewChatMetrics() *chatMetrics { //nolint:revive
+	return &chatMetrics{
+		webSocketWireBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "chat_websocket_wire_bytes_total",
+			Help: "Bytes sent and received over WebSocket connections, as is on network, compressed if negotiated.",
+		}, []string{labelDirection}),
+		webSocketPayloadBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "chat_websocket_payload_bytes_total",
+			Help: "Bytes of WebSocket messages payload sent and received, uncompressed.",
+		}, []string{labelDirection}),
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "chat_rate_limited_messages_total",
+			Help: "Messages from clients over rate limit, by exceeded limit scope and action taken.",
+		}, []string{labelScope, labelAction}),
+		backpressure: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "chat_backpressure_total",
+			Help: "Messages dropped and clients disconnected because client does not read messages fast enough.",
+		}, []string{labelPolicy, labelResult}),
+	}
+}
+
+func (m *chatMetrics) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.webSocketWireBytes,
+		m.webSocketPayloadBytes,
+		m.rateLimited,
+		m.backpressure,
+	}
+}
+
+func (m *chatMetrics) AddWebSocketWireBytes(direction string, bytes int) {
+	m.webSocketWireBytes.WithLabelValues(direction).Add(float64(bytes))
+}
+
+func (m *chatMetrics) AddWebSocketPayloadBytes(direction string, bytes int) {
+	m.webSocketPayloadBytes.WithLabelValues(direction).Add(float64(bytes))
+}
+
+func (m *chatMetrics) IncRateLimited(scope, action string) {
+	m.rateLimited.WithLabelValues(scope, action).Inc()
+}
+
+func (m *chatMetrics) IncBackpressure(policy, result string) {
+	m.backpressure.WithLabelValues(policy, result).Inc()
+}
@@ -2,59 +2,294 @@ package pubsub
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
+
+	"github.com/dark705/go-ws-chat/internal/chat"
+)
+
+const (
+	presenceChanelBufferSize     = 64
+	subscriptionChanelBufferSize = 16
 )
 
-var errNotFound = errors.New("not found")
+type Config struct {
+	OfflineQueueSize          int
+	OfflineQueueTTLSeconds    int
+	OfflineQueueMaxRecipients int
+	// OfflineQueueMaxBytes is a limit of approximate size of all queued envelopes
+	OfflineQueueMaxBytes int
+}
+
+// subscription is a channel of one device of ID. Envelopes are sent under its own lock, not under lock of hub, so slow
+// subscriber blocks only publishers to it.
+type subscription struct {
+	ch     chan chat.Envelope
+	done   <-chan struct{} // ctx.Done() of subscriber
+	mu     sync.Mutex
+	closed bool
+}
+
+// send waits until subscriber takes envelope or is gone.
+func (s *subscription) send(envelope chat.Envelope) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.ch <- envelope:
+	case <-s.done: // subscriber is gone, channel is not read anymore
+	}
+}
+
+func (s *subscription) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	close(s.ch)
+}
+
+type queuedEnvelope struct {
+	envelope chat.Envelope
+	size     int
+	expireAt time.Time
+}
 
 type inmemory struct {
 	logger Logger
+	config Config
 	mu     sync.Mutex
-	chs    map[string]chan string
+	subs   map[string]map[*subscription]struct{} // subscriptions of ID from devices
+	queues map[string][]queuedEnvelope
+	// seen is a time ID was online last time, messages are queued only for IDs seen not earlier than TTL ago, it is
+	// recorded only when offline queue is enabled
+	seen        map[string]time.Time
+	queuedBytes int
+	lastSweep   time.Time
+	presence    map[chan chat.PresenceEvent]struct{}
 }
 
-func NewInmemory(logger Logger) *inmemory {
+func NewInmemory(config Config, logger Logger) *inmemory {
 	return &inmemory{
-		logger: logger,
-		chs:    make(map[string]chan string),
+		logger:    logger,
+		config:    config,
+		subs:      make(map[string]map[*subscription]struct{}),
+		queues:    make(map[string][]queuedEnvelope),
+		seen:      make(map[string]time.Time),
+		lastSweep: time.Now(),
+		presence:  make(map[chan chat.PresenceEvent]struct{}),
 	}
 }
 
-func (ps *inmemory) Sub(ctx context.Context, id string) (chan string, error) { //nolint:varnamelen
+func (ps *inmemory) Sub(ctx context.Context, id string) (chan chat.Envelope, error) { //nolint:varnamelen
 	ps.mu.Lock()
 	defer ps.mu.Unlock()
-	ch := make(chan string) //nolint:varnamelen
-	ps.chs[id] = ch
+	queue := ps.dequeue(id)
+	sub := &subscription{
+		ch:   make(chan chat.Envelope, max(len(queue), subscriptionChanelBufferSize)),
+		done: ctx.Done(),
+	}
+	for _, envelope := range queue {
+		sub.ch <- envelope
+	}
+	subs, found := ps.subs[id]
+	if !found {
+		subs = make(map[*subscription]struct{})
+		ps.subs[id] = subs
+		ps.notifyPresence(ctx, chat.PresenceEvent{ClientID: id, Online: true})
+	}
+	subs[sub] = struct{}{}
 	ps.logger.InfofContext(ctx,
-		"pubsub, inmemory, Sub, subscribed ID: %s, total: %d",
-		id, len(ps.chs))
+		"pubsub, inmemory, Sub, subscribed ID: %s, subscriptions of ID: %d, total: %d, flushed from offline queue: %d",
+		id, len(subs), len(ps.subs), len(queue))
 
 	go func() {
 		<-ctx.Done()
 		ps.mu.Lock()
-		defer ps.mu.Unlock()
-		close(ch)
-		delete(ps.chs, id)
+		delete(ps.subs[id], sub)
+		if len(ps.subs[id]) == 0 {
+			delete(ps.subs, id)
+			if ps.config.OfflineQueueSize > 0 {
+				ps.seen[id] = time.Now()
+				ps.sweep() // enqueue may not be called, e.g. for ephemeral envelopes only
+			}
+			ps.notifyPresence(ctx, chat.PresenceEvent{ClientID: id, Online: false})
+		}
 		ps.logger.InfofContext(ctx,
 			"pubsub, inmemory, Sub, unsubscribed ID: %s , total: %d",
-			id, len(ps.chs))
+			id, len(ps.subs))
+		ps.mu.Unlock()
+		sub.close() // after lock of hub is released, as publisher may wait for subscriber
 	}()
 
-	return ch, nil
+	return sub.ch, nil
 }
 
-func (ps *inmemory) Pub(ctx context.Context, id, message string) error { //nolint:varnamelen
+// Pub sends envelope to all subscriptions of ID one by one, without lock of hub, so slow subscriber does not block
+// publishers to other IDs.
+func (ps *inmemory) Pub(ctx context.Context, id string, envelope chat.Envelope) error { //nolint:varnamelen
 	ps.mu.Lock()
-	defer ps.mu.Unlock()
-
-	ch, found := ps.chs[id]
+	subs, found := ps.subs[id]
 	if !found {
-		return fmt.Errorf("subscriber ID: %s, %w", id, errNotFound)
+		defer ps.mu.Unlock()
+		if !ps.enqueue(id, envelope) {
+			return fmt.Errorf("subscriber ID: %s, %w", id, chat.ErrSubscriberNotFound)
+		}
+		ps.logger.DebugfContext(ctx, "pubsub, inmemory, Pub, subscriber ID: %s offline, queued message ID: %s, queue size: %d",
+			id, envelope.ID, len(ps.queues[id]))
+
+		return nil
+	}
+	receivers := make([]*subscription, 0, len(subs))
+	for sub := range subs {
+		receivers = append(receivers, sub)
+	}
+	ps.mu.Unlock()
+
+	ps.logger.DebugfContext(ctx, "pubsub, inmemory, Pub, subscriber ID: %s send message ID: %s, subscriptions: %d",
+		id, envelope.ID, len(receivers))
+	for _, sub := range receivers {
+		sub.send(envelope)
 	}
-	ps.logger.DebugfContext(ctx, "pubsub, inmemory, Pub, subscriber ID: %s send: %s", id, message)
-	ch <- message
 
 	return nil
 }
+
+func (ps *inmemory) Online(_ context.Context) ([]string, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ids := make([]string, 0, len(ps.subs))
+	for id := range ps.subs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+func (ps *inmemory) SubPresence(ctx context.Context) (chan chat.PresenceEvent, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ch := make(chan chat.PresenceEvent, presenceChanelBufferSize) //nolint:varnamelen
+	ps.presence[ch] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		ps.mu.Lock()
+		defer ps.mu.Unlock()
+		close(ch)
+		delete(ps.presence, ch)
+	}()
+
+	return ch, nil
+}
+
+// notifyPresence sends event to presence subscribers, event is dropped for subscriber with full channel.
+func (ps *inmemory) notifyPresence(ctx context.Context, event chat.PresenceEvent) {
+	for ch := range ps.presence {
+		select {
+		case ch <- event:
+		default:
+			ps.logger.DebugfContext(ctx, "pubsub, inmemory, notifyPresence, subscriber channel is full, event dropped")
+		}
+	}
+}
+
+// enqueue stores envelope for offline subscriber, which was online not earlier than TTL ago, the oldest envelope is
+// dropped when queue is full.
+func (ps *inmemory) enqueue(id string, envelope chat.Envelope) bool { //nolint:varnamelen
+	if ps.config.OfflineQueueSize <= 0 || envelope.Ephemeral {
+		return false
+	}
+	ps.sweep()
+
+	if _, found := ps.seen[id]; !found {
+		return false
+	}
+	queue, found := ps.queues[id]
+	if !found && len(ps.queues) >= ps.config.OfflineQueueMaxRecipients {
+		return false
+	}
+	size := envelopeSize(envelope)
+	if len(queue) >= ps.config.OfflineQueueSize {
+		ps.queuedBytes -= queue[0].size
+		queue = queue[1:]
+	}
+	if ps.queuedBytes+size > ps.config.OfflineQueueMaxBytes {
+		ps.queues[id] = queue
+
+		return false
+	}
+	ps.queuedBytes += size
+	ps.queues[id] = append(queue, queuedEnvelope{
+		envelope: envelope,
+		size:     size,
+		expireAt: time.Now().Add(ps.ttl()),
+	})
+
+	return true
+}
+
+// dequeue returns not expired envelopes of offline subscriber in order they were published.
+func (ps *inmemory) dequeue(id string) []chat.Envelope { //nolint:varnamelen
+	queue := ps.queues[id]
+	delete(ps.queues, id)
+
+	now := time.Now()
+	envelopes := make([]chat.Envelope, 0, len(queue))
+	for _, queued := range queue {
+		ps.queuedBytes -= queued.size
+		if now.Before(queued.expireAt) {
+			envelopes = append(envelopes, queued.envelope)
+		}
+	}
+
+	return envelopes
+}
+
+// sweep drops expired envelopes of all offline subscribers and IDs not seen for TTL, not often than once per TTL.
+func (ps *inmemory) sweep() {
+	now := time.Now()
+	if now.Sub(ps.lastSweep) < ps.ttl() {
+		return
+	}
+	ps.lastSweep = now
+
+	for id, seenAt := range ps.seen {
+		if now.Sub(seenAt) >= ps.ttl() {
+			delete(ps.seen, id)
+		}
+	}
+	for id, queue := range ps.queues {
+		for len(queue) > 0 && !now.Before(queue[0].expireAt) {
+			ps.queuedBytes -= queue[0].size
+			queue = queue[1:]
+		}
+		if len(queue) == 0 {
+			delete(ps.queues, id)
+
+			continue
+		}
+		ps.queues[id] = queue
+	}
+}
+
+// envelopeSize is approximate size of envelope in memory, by its texts and file data.
+func envelopeSize(envelope chat.Envelope) int {
+	size := len(envelope.ID) + len(envelope.Ref) + len(envelope.From) + len(envelope.To) + len(envelope.Room) +
+		len(envelope.Text)
+	if envelope.File != nil {
+		size += len(envelope.File.ID) + len(envelope.File.Name) + len(envelope.File.MimeType) + len(envelope.File.Data)
+	}
+
+	return size
+}
+
+func (ps *inmemory) ttl() time.Duration {
+	return time.Duration(ps.config.OfflineQueueTTLSeconds) * time.Second
+}
@@ -0,0 +1,188 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dark705/go-ws-chat/internal/chat"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) DebugfContext(context.Context, string, ...any) {}
+func (nopLogger) InfofContext(context.Context, string, ...any)  {}
+
+func newTestInmemory(config Config) *inmemory {
+	if config.OfflineQueueTTLSeconds == 0 {
+		config.OfflineQueueTTLSeconds = 60
+	}
+	if config.OfflineQueueMaxRecipients == 0 {
+		config.OfflineQueueMaxRecipients = 100
+	}
+	if config.OfflineQueueMaxBytes == 0 {
+		config.OfflineQueueMaxBytes = 1 << 20
+	}
+
+	return NewInmemory(config, nopLogger{})
+}
+
+// subAndLeave subscribes id and unsubscribes it, so id is seen and offline.
+func subAndLeave(t *testing.T, ps *inmemory, id string) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := ps.Sub(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	for range ch { //nolint:revive
+	}
+}
+
+func TestInmemoryPubOffline(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  Config
+		seen    bool
+		text    string
+		wantErr error
+	}{
+		{name: "never seen ID", config: Config{OfflineQueueSize: 10}, wantErr: chat.ErrSubscriberNotFound},
+		{name: "seen ID is queued", config: Config{OfflineQueueSize: 10}, seen: true},
+		{name: "queue disabled", config: Config{}, seen: true, wantErr: chat.ErrSubscriberNotFound},
+		{
+			name:    "queued bytes limit",
+			config:  Config{OfflineQueueSize: 10, OfflineQueueMaxBytes: 100},
+			seen:    true,
+			text:    strings.Repeat("x", 200),
+			wantErr: chat.ErrSubscriberNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			ps := newTestInmemory(test.config)
+			if test.seen {
+				subAndLeave(t, ps, "alice")
+			}
+
+			err := ps.Pub(context.Background(), "alice", chat.Envelope{ID: "1", Text: test.text})
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Pub() error = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestInmemorySeenOnUnsubscribe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		config    Config
+		staleSeen string // ID seen earlier than TTL ago
+		wantSeen  []string
+	}{
+		{name: "queue disabled", config: Config{}, wantSeen: nil},
+		{name: "seen", config: Config{OfflineQueueSize: 10}, wantSeen: []string{"alice"}},
+		{name: "stale is swept", config: Config{OfflineQueueSize: 10}, staleSeen: "bob", wantSeen: []string{"alice"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			ps := newTestInmemory(test.config)
+			if test.staleSeen != "" {
+				ps.seen[test.staleSeen] = time.Now().Add(-time.Hour)
+				ps.lastSweep = time.Now().Add(-time.Hour)
+			}
+
+			subAndLeave(t, ps, "alice")
+
+			ps.mu.Lock()
+			defer ps.mu.Unlock()
+			if len(ps.seen) != len(test.wantSeen) {
+				t.Fatalf("seen = %v, want %v", ps.seen, test.wantSeen)
+			}
+			for _, id := range test.wantSeen {
+				if _, found := ps.seen[id]; !found {
+					t.Fatalf("seen = %v, want %v", ps.seen, test.wantSeen)
+				}
+			}
+		})
+	}
+}
+
+func TestInmemoryQueueFlushedOnSub(t *testing.T) {
+	t.Parallel()
+	ps := newTestInmemory(Config{OfflineQueueSize: 2})
+	subAndLeave(t, ps, "alice")
+
+	for _, id := range []string{"1", "2", "3"} {
+		err := ps.Pub(context.Background(), "alice", chat.Envelope{ID: id, Text: "hi"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := ps.Sub(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"2", "3"} {
+		if envelope := <-ch; envelope.ID != want {
+			t.Fatalf("got envelope %s, want %s", envelope.ID, want)
+		}
+	}
+	if ps.queuedBytes != 0 {
+		t.Fatalf("queuedBytes = %d after flush, want 0", ps.queuedBytes)
+	}
+}
+
+func TestInmemorySlowSubscriberDoesNotBlockHub(t *testing.T) {
+	t.Parallel()
+	ps := newTestInmemory(Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := ps.Sub(ctx, "slow") // never read
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast, err := ps.Sub(ctx, "fast")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for {
+			if ps.Pub(ctx, "slow", chat.Envelope{ID: "x"}) != nil || ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = ps.Online(ctx)
+		_ = ps.Pub(ctx, "fast", chat.Envelope{ID: "1"})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("hub is blocked by slow subscriber")
+	}
+	if envelope := <-fast; envelope.ID != "1" {
+		t.Fatalf("got envelope %s, want 1", envelope.ID)
+	}
+}
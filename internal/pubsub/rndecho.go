@@ -2,24 +2,40 @@ package pubsub
 
 import (
 	"context"
+
+	"github.com/dark705/go-ws-chat/internal/chat"
 )
 
 type rndecho struct {
 	logger Logger
-	ch     chan string
+	ch     chan chat.Envelope
 }
 
 func NewRndEcho(logger Logger) *rndecho {
-	return &rndecho{logger: logger, ch: make(chan string)}
+	return &rndecho{logger: logger, ch: make(chan chat.Envelope)}
 }
 
-func (ps *rndecho) Sub(_ context.Context, _ string) (chan string, error) {
+func (ps *rndecho) Sub(_ context.Context, _ string) (chan chat.Envelope, error) {
 	return ps.ch, nil
 }
 
-func (ps *rndecho) Pub(ctx context.Context, id, message string) error {
-	ps.logger.DebugfContext(ctx, "pubsub, rndecho, Pub, subscriber with id: %s send: %s", id, message)
-	ps.ch <- message
+func (ps *rndecho) Pub(ctx context.Context, id string, envelope chat.Envelope) error {
+	ps.logger.DebugfContext(ctx, "pubsub, rndecho, Pub, subscriber with id: %s send message ID: %s", id, envelope.ID)
+	ps.ch <- envelope
 
 	return nil
 }
+
+func (ps *rndecho) Online(_ context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (ps *rndecho) SubPresence(ctx context.Context) (chan chat.PresenceEvent, error) {
+	ch := make(chan chat.PresenceEvent) //nolint:varnamelen
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+
+	return ch, nil
+}
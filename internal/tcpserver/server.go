@@ -0,0 +1,148 @@
+package tcpserver
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"sync"
+	"time"
+)
+
+type Logger interface {
+	Debugf(format string, args ...any)
+	DebugfContext(ctx context.Context, format string, args ...any)
+
+	Infof(format string, args ...any)
+	InfofContext(ctx context.Context, format string, args ...any)
+
+	Warnf(format string, args ...any)
+	WarnfContext(ctx context.Context, format string, args ...any)
+
+	Errorf(format string, args ...any)
+	ErrorfContext(ctx context.Context, format string, args ...any)
+
+	Fatalf(format string, args ...any)
+	FatalfContext(ctx context.Context, format string, args ...any)
+}
+
+const (
+	shutdownMaxTimeout = 5 * time.Second
+)
+
+// Handler serves accepted connection, it must return when connection is closed.
+type Handler interface {
+	ServeConn(ctx context.Context, conn net.Conn)
+}
+
+type Server struct {
+	logger   Logger
+	config   Config
+	handler  Handler
+	listener net.Listener
+	cancel   context.CancelFunc
+	mu       sync.Mutex
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+}
+
+type Config struct {
+	Name          string
+	TCPListenIP   string
+	TCPListenPort string
+	// MaxConnections is a limit of concurrent connections, connection over it is closed right after accept, 0 - no limit
+	MaxConnections int
+}
+
+func NewServer(config Config, logger Logger, handler Handler) *Server {
+	return &Server{
+		logger:  logger,
+		config:  config,
+		handler: handler,
+		conns:   make(map[net.Conn]struct{}),
+	}
+}
+
+func (s *Server) Run() {
+	address := s.config.TCPListenIP + ":" + s.config.TCPListenPort
+	s.logger.Infof("%s TCPServer, start on: %s", s.config.Name, address)
+	listener, err := net.Listen("tcp", address)
+	failOnError(err, s.config.Name+" TCPServer, fail open port")
+	s.listener = listener
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					s.logger.Errorf("%s TCPServer, fail accept: %s", s.config.Name, err)
+				}
+
+				return
+			}
+			s.serve(ctx, conn)
+		}
+	}()
+}
+
+// Stop closes listener and all connections, then waits for handlers to return.
+func (s *Server) Stop() {
+	s.logger.Infof(s.config.Name + " TCPServer, stop...")
+	err := s.listener.Close()
+	if err != nil {
+		s.logger.Errorf("%s TCPServer, fail close listener: %s", s.config.Name, err)
+	}
+	s.cancel()
+
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close() //nolint:errcheck
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		s.logger.Infof(s.config.Name + " TCPServer, success stop")
+	case <-time.After(shutdownMaxTimeout):
+		s.logger.Errorf(s.config.Name + " TCPServer, fail stop")
+	}
+}
+
+func (s *Server) serve(ctx context.Context, conn net.Conn) {
+	s.mu.Lock()
+	if s.config.MaxConnections > 0 && len(s.conns) >= s.config.MaxConnections {
+		s.mu.Unlock()
+		s.logger.Warnf("%s TCPServer, connections limit: %d reached, reject connection from: %s",
+			s.config.Name, s.config.MaxConnections, conn.RemoteAddr())
+		conn.Close() //nolint:errcheck
+
+		return
+	}
+	s.conns[conn] = struct{}{}
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer func() {
+			conn.Close() //nolint:errcheck
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+			s.wg.Done()
+		}()
+		s.handler.ServeConn(ctx, conn)
+	}()
+}
+
+func failOnError(err error, message string) {
+	if err != nil {
+		log.Fatalf("%s: %s", message, err)
+	}
+}
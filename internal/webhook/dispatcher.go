@@ -0,0 +1,195 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dark705/go-ws-chat/internal/chat"
+)
+
+const (
+	// SignatureHeader is hex HMAC-SHA256 of request body with shared secret, prefixed with "sha256=".
+	SignatureHeader = "X-Chat-Signature"
+	// EventHeader is a type of event in request body.
+	EventHeader = "X-Chat-Event"
+	// DeliveryHeader is an ID of delivery, it is the same for retries, so receiver can skip duplicates.
+	DeliveryHeader = "X-Chat-Delivery"
+
+	deliveryIDSizeBytes = 16
+)
+
+var errUnexpectedStatus = errors.New("unexpected status")
+
+type Config struct {
+	URLs                     []string
+	Secret                   string
+	QueueSize                int
+	MaxRetries               int
+	RetryBackoffMilliseconds int
+	TimeoutMilliseconds      int
+}
+
+type delivery struct {
+	id        string
+	eventType string
+	body      []byte
+}
+
+type dispatcher struct {
+	logger Logger
+	config Config
+	client *http.Client
+	queues map[string]chan delivery
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher POSTs chat events as JSON to each of URLs. Each URL has own bounded queue, event is dropped when queue
+// is full. Failed request is retried with exponential backoff on network error, 429 and 5xx statuses.
+func NewDispatcher(config Config, logger Logger) *dispatcher { //nolint:revive
+	queues := make(map[string]chan delivery, len(config.URLs))
+	for _, url := range config.URLs {
+		queues[url] = make(chan delivery, config.QueueSize)
+	}
+
+	return &dispatcher{
+		logger: logger,
+		config: config,
+		client: &http.Client{Timeout: time.Duration(config.TimeoutMilliseconds) * time.Millisecond},
+		queues: queues,
+	}
+}
+
+func (d *dispatcher) Run() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+
+	for url, queue := range d.queues {
+		d.logger.InfofContext(ctx, "webhook, dispatcher, Run, start delivery to: %s", url)
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case queued := <-queue:
+					d.deliver(ctx, url, queued)
+				}
+			}
+		}()
+	}
+}
+
+// Stop interrupts current deliveries, queued events are dropped.
+func (d *dispatcher) Stop() {
+	d.logger.InfofContext(context.Background(), "webhook, dispatcher, Stop, stop...")
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.wg.Wait()
+	d.logger.InfofContext(context.Background(), "webhook, dispatcher, Stop, success stop")
+}
+
+func (d *dispatcher) Notify(ctx context.Context, event chat.Event) {
+	if len(d.queues) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		d.logger.ErrorfContext(ctx, "webhook, dispatcher, Notify, json.Marshal, error: %s", err)
+
+		return
+	}
+	queued := delivery{id: newDeliveryID(), eventType: event.Type, body: body}
+
+	for url, queue := range d.queues {
+		select {
+		case queue <- queued:
+		default:
+			d.logger.WarnfContext(ctx, "webhook, dispatcher, Notify, queue of: %s is full, event dropped: %s",
+				url, event.Type)
+		}
+	}
+}
+
+// deliver sends queued event to url, retries until success, permanent failure, MaxRetries or ctx is done.
+func (d *dispatcher) deliver(ctx context.Context, url string, queued delivery) {
+	backoff := time.Duration(d.config.RetryBackoffMilliseconds) * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		retry, err := d.post(ctx, url, queued)
+		if err == nil {
+			d.logger.DebugfContext(ctx, "webhook, dispatcher, deliver, delivered: %s to: %s", queued.id, url)
+
+			return
+		}
+		if !retry || attempt >= d.config.MaxRetries {
+			d.logger.ErrorfContext(ctx, "webhook, dispatcher, deliver, fail deliver: %s to: %s, attempts: %d, error: %s",
+				queued.id, url, attempt+1, err)
+
+			return
+		}
+		d.logger.WarnfContext(ctx, "webhook, dispatcher, deliver, retry: %s to: %s in: %s, error: %s",
+			queued.id, url, backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post makes one delivery attempt, retry reports whether failure may be temporary.
+func (d *dispatcher) post(ctx context.Context, url string, queued delivery) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(queued.body))
+	if err != nil {
+		return false, fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, queued.eventType)
+	request.Header.Set(DeliveryHeader, queued.id)
+	request.Header.Set(SignatureHeader, "sha256="+Sign(d.config.Secret, queued.body))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return true, fmt.Errorf("client.Do: %w", err)
+	}
+	_ = response.Body.Close()
+
+	switch {
+	case response.StatusCode >= http.StatusOK && response.StatusCode < http.StatusMultipleChoices:
+		return false, nil
+	case response.StatusCode == http.StatusTooManyRequests, response.StatusCode >= http.StatusInternalServerError:
+		return true, fmt.Errorf("%w: %d", errUnexpectedStatus, response.StatusCode)
+	default:
+		return false, fmt.Errorf("%w: %d", errUnexpectedStatus, response.StatusCode)
+	}
+}
+
+// Sign returns hex HMAC-SHA256 of body with secret, receiver compares it with SignatureHeader without prefix.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newDeliveryID() string {
+	id := make([]byte, deliveryIDSizeBytes)
+	_, _ = rand.Read(id) // crypto/rand.Read never returns an error
+
+	return hex.EncodeToString(id)
+}
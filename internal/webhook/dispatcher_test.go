@@ -0,0 +1,185 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dark705/go-ws-chat/internal/chat"
+)
+
+const testSecret = "secret"
+
+type nopLogger struct{}
+
+func (nopLogger) DebugfContext(context.Context, string, ...any) {}
+func (nopLogger) InfofContext(context.Context, string, ...any)  {}
+func (nopLogger) WarnfContext(context.Context, string, ...any)  {}
+func (nopLogger) ErrorfContext(context.Context, string, ...any) {}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver answers requests with statuses in order, the last status is repeated.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedRequest
+}
+
+func (r *receiver) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
+	body, _ := io.ReadAll(request.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, receivedRequest{header: request.Header.Clone(), body: body})
+	status := r.statuses[min(len(r.requests), len(r.statuses))-1]
+	responseWriter.WriteHeader(status)
+}
+
+func (r *receiver) received() []receivedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]receivedRequest(nil), r.requests...)
+}
+
+func newTestDispatcher(url string, maxRetries int) *dispatcher {
+	return NewDispatcher(Config{
+		URLs:                     []string{url},
+		Secret:                   testSecret,
+		QueueSize:                10,
+		MaxRetries:               maxRetries,
+		RetryBackoffMilliseconds: 1,
+		TimeoutMilliseconds:      1000,
+	}, nopLogger{})
+}
+
+func TestDispatcherDeliverRetries(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		statuses     []int
+		maxRetries   int
+		wantAttempts int
+	}{
+		{name: "success", statuses: []int{http.StatusOK}, maxRetries: 3, wantAttempts: 1},
+		{name: "accepted", statuses: []int{http.StatusAccepted}, maxRetries: 3, wantAttempts: 1},
+		{
+			name:         "5xx is retried",
+			statuses:     []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK},
+			maxRetries:   3,
+			wantAttempts: 3,
+		},
+		{
+			name:         "429 is retried",
+			statuses:     []int{http.StatusTooManyRequests, http.StatusNoContent},
+			maxRetries:   3,
+			wantAttempts: 2,
+		},
+		{name: "4xx is not retried", statuses: []int{http.StatusBadRequest}, maxRetries: 3, wantAttempts: 1},
+		{name: "redirect is not retried", statuses: []int{http.StatusNotModified}, maxRetries: 3, wantAttempts: 1},
+		{
+			name:         "retries are limited",
+			statuses:     []int{http.StatusServiceUnavailable},
+			maxRetries:   2,
+			wantAttempts: 3,
+		},
+		{name: "no retries", statuses: []int{http.StatusServiceUnavailable}, wantAttempts: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			receiver := &receiver{statuses: test.statuses}
+			server := httptest.NewServer(receiver)
+			defer server.Close()
+
+			queued := delivery{id: "delivery-1", eventType: chat.EventMessageDelivered, body: []byte(`{"type":"x"}`)}
+			newTestDispatcher(server.URL, test.maxRetries).deliver(context.Background(), server.URL, queued)
+
+			requests := receiver.received()
+			if len(requests) != test.wantAttempts {
+				t.Fatalf("attempts = %d, want %d", len(requests), test.wantAttempts)
+			}
+			for _, request := range requests {
+				if got := request.header.Get(DeliveryHeader); got != queued.id {
+					t.Fatalf("%s = %q, want the same for retries: %q", DeliveryHeader, got, queued.id)
+				}
+			}
+		})
+	}
+}
+
+func TestDispatcherDeliverNetworkErrorIsRetried(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	start := time.Now()
+	d := newTestDispatcher(url, 2)
+	d.config.RetryBackoffMilliseconds = 20
+	d.deliver(context.Background(), url, delivery{id: "1", body: []byte("{}")})
+
+	// backoff is exponential: 20ms, then 40ms
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Fatalf("deliver() returned after %s, want 2 retries with backoff", elapsed)
+	}
+}
+
+func TestDispatcherNotifySignsEvent(t *testing.T) {
+	t.Parallel()
+	receiver := &receiver{statuses: []int{http.StatusOK}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	d := newTestDispatcher(server.URL, 0)
+	d.Run()
+	defer d.Stop()
+
+	event := chat.Event{Type: chat.EventMessageDelivered, ClientID: "alice", MessageID: "1", To: "bob"}
+	d.Notify(context.Background(), event)
+
+	deadline := time.Now().Add(time.Second)
+	for len(receiver.received()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("event is not delivered")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	request := receiver.received()[0]
+	if got, want := request.header.Get(SignatureHeader), "sha256="+Sign(testSecret, request.body); got != want {
+		t.Fatalf("%s = %q, want %q", SignatureHeader, got, want)
+	}
+	if got := request.header.Get(EventHeader); got != chat.EventMessageDelivered {
+		t.Fatalf("%s = %q, want %q", EventHeader, got, chat.EventMessageDelivered)
+	}
+	if request.header.Get(DeliveryHeader) == "" {
+		t.Fatalf("%s is empty", DeliveryHeader)
+	}
+	var received chat.Event
+	err := json.Unmarshal(request.body, &received)
+	if err != nil || received.ClientID != "alice" || received.MessageID != "1" {
+		t.Fatalf("body = %s, error: %v", request.body, err)
+	}
+}
+
+func TestSign(t *testing.T) {
+	t.Parallel()
+
+	// HMAC-SHA256 test vector of RFC 4231, test case 2
+	got := Sign("Jefe", []byte("what do ya want for nothing?"))
+	if want := "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"; got != want {
+		t.Fatalf("Sign() = %s, want %s", got, want)
+	}
+}